@@ -1,36 +1,49 @@
 package resource
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
-	"github.com/Masterminds/semver"
-	"github.com/cppforlife/go-semi-semantic/version"
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 )
 
 type CheckCommand struct {
 	github GitHub
+
+	now func() time.Time
 }
 
 func NewCheckCommand(github GitHub) *CheckCommand {
 	return &CheckCommand{
 		github: github,
+		now:    time.Now,
 	}
 }
 
+// SetClock replaces the clock the age of releases is measured with.
+func (c *CheckCommand) SetClock(now func() time.Time) {
+	c.now = now
+}
+
 func SortByVersion(releases []*github.RepositoryRelease, versionParser *versionParser) {
 	sort.Slice(releases, func(i, j int) bool {
-		first, err := version.NewVersionFromString(versionParser.parse(*releases[i].TagName))
+		first, err := versionParser.version(*releases[i].TagName)
 		if err != nil {
 			return true
 		}
 
-		second, err := version.NewVersionFromString(versionParser.parse(*releases[j].TagName))
+		second, err := versionParser.version(*releases[j].TagName)
 		if err != nil {
 			return false
 		}
 
-		return first.IsLt(second)
+		return first.compare(second) < 0
 	})
 }
 
@@ -42,8 +55,219 @@ func SortByTimestamp(releases []*github.RepositoryRelease) {
 	})
 }
 
-func (c *CheckCommand) Run(request CheckRequest) ([]Version, error) {
-	releases, err := c.github.ListReleases()
+// hasRequiredAssets reports whether each of the globs matches an asset of
+// the release that has finished uploading. Releases listed through the REST
+// API come with their assets; those listed through GraphQL do not, as it
+// does not expose the state of assets, so theirs are listed separately.
+func (c *CheckCommand) hasRequiredAssets(ctx context.Context, release *github.RepositoryRelease, globs []string) (bool, error) {
+	assets := release.Assets
+	if assets == nil {
+		var err error
+		assets, err = c.github.ListReleaseAssets(ctx, *release)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, glob := range globs {
+		found := false
+		for _, asset := range assets {
+			if asset.GetState() != "uploaded" {
+				continue
+			}
+
+			if matched, _ := filepath.Match(glob, asset.GetName()); matched {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// requestFilter holds the filters that cost a request per release, which are
+// only applied to the releases that check would otherwise output.
+type requestFilter struct {
+	requiredAssets  []string
+	targetCommitish *regexp.Regexp
+	reachableFrom   string
+
+	// Several releases may be tagged on the same commit, which only needs
+	// to be compared with the branch once.
+	reachableCommits map[string]bool
+}
+
+// accepts reports whether the release passes the filters.
+func (c *CheckCommand) accepts(ctx context.Context, release *github.RepositoryRelease, filter requestFilter) (bool, error) {
+	if len(filter.requiredAssets) > 0 {
+		found, err := c.hasRequiredAssets(ctx, release, filter.requiredAssets)
+		if err != nil || !found {
+			return false, err
+		}
+	}
+
+	if filter.targetCommitish != nil {
+		commitish, err := c.targetCommitish(ctx, release)
+		if err != nil {
+			return false, err
+		}
+		if !filter.targetCommitish.MatchString(commitish) {
+			return false, nil
+		}
+	}
+
+	if filter.reachableFrom != "" {
+		return c.isReachable(ctx, release, filter.reachableFrom, filter.reachableCommits)
+	}
+
+	return true, nil
+}
+
+// targetCommitish returns the branch or commit the release was created
+// from. Releases listed through GraphQL lack it, as the API does not expose
+// it, so it is fetched for each of them that check would output.
+func (c *CheckCommand) targetCommitish(ctx context.Context, release *github.RepositoryRelease) (string, error) {
+	if release.TargetCommitish != nil {
+		return *release.TargetCommitish, nil
+	}
+
+	full, err := c.github.GetRelease(ctx, int(release.GetID()))
+	if err != nil {
+		return "", err
+	}
+
+	return full.GetTargetCommitish(), nil
+}
+
+// isReachable reports whether the commit the release is tagged on is in the
+// history of the branch. The tags of drafts need not exist yet, and drafts
+// without one are not reachable.
+func (c *CheckCommand) isReachable(ctx context.Context, release *github.RepositoryRelease, branch string, reachableCommits map[string]bool) (bool, error) {
+	if release.GetTagName() == "" {
+		return false, nil
+	}
+
+	sha, err := c.github.ResolveTagToCommitSHA(ctx, release.GetTagName())
+	if err != nil {
+		if release.GetDraft() {
+			return false, nil
+		}
+		return false, err
+	}
+
+	reachable, found := reachableCommits[sha]
+	if !found {
+		reachable, err = c.github.IsCommitReachable(ctx, sha, branch)
+		if err != nil {
+			return false, err
+		}
+		reachableCommits[sha] = reachable
+	}
+
+	return reachable, nil
+}
+
+// contentFilter selects releases by their name, body and author.
+type contentFilter struct {
+	name         *regexp.Regexp
+	body         *regexp.Regexp
+	bodyExcludes []string
+	authors      []string
+}
+
+func newContentFilter(source Source) (contentFilter, error) {
+	filter := contentFilter{
+		bodyExcludes: source.BodyExcludes,
+		authors:      source.Authors,
+	}
+
+	var err error
+	if source.NameFilter != "" {
+		filter.name, err = regexp.Compile(source.NameFilter)
+		if err != nil {
+			return contentFilter{}, fmt.Errorf("invalid name_filter: %w", err)
+		}
+	}
+
+	if source.BodyFilter != "" {
+		filter.body, err = regexp.Compile(source.BodyFilter)
+		if err != nil {
+			return contentFilter{}, fmt.Errorf("invalid body_filter: %w", err)
+		}
+	}
+
+	return filter, nil
+}
+
+func (f contentFilter) matches(release *github.RepositoryRelease) bool {
+	if f.name != nil && !f.name.MatchString(release.GetName()) {
+		return false
+	}
+
+	if f.body != nil && !f.body.MatchString(release.GetBody()) {
+		return false
+	}
+
+	for _, exclude := range f.bodyExcludes {
+		if strings.Contains(release.GetBody(), exclude) {
+			return false
+		}
+	}
+
+	if len(f.authors) > 0 {
+		return slices.ContainsFunc(f.authors, func(author string) bool {
+			return sameLogin(author, release.GetAuthor().GetLogin())
+		})
+	}
+
+	return true
+}
+
+// sameLogin compares logins case-insensitively, as GitHub does. Bots are
+// listed as e.g. github-actions[bot] by the REST API but as github-actions
+// by the GraphQL API, so the suffix is ignored.
+func sameLogin(a, b string) bool {
+	a = strings.TrimSuffix(strings.ToLower(a), "[bot]")
+	b = strings.TrimSuffix(strings.ToLower(b), "[bot]")
+	return a != "" && a == b
+}
+
+func (c *CheckCommand) Run(ctx context.Context, request CheckRequest) ([]Version, error) {
+	for _, glob := range request.Source.RequiredAssets {
+		if _, err := filepath.Match(glob, ""); err != nil || glob == "" {
+			return []Version{}, fmt.Errorf("invalid required_assets glob '%s'", glob)
+		}
+	}
+
+	minAge, err := parseDuration("min_age", request.Source.MinAge, 0)
+	if err != nil {
+		return []Version{}, err
+	}
+	publishedBefore := c.now().Add(-minAge)
+
+	contentFilter, err := newContentFilter(request.Source)
+	if err != nil {
+		return []Version{}, err
+	}
+
+	filter := requestFilter{
+		requiredAssets:   request.Source.RequiredAssets,
+		reachableFrom:    request.Source.ReachableFrom,
+		reachableCommits: map[string]bool{},
+	}
+	if request.Source.TargetCommitish != "" {
+		filter.targetCommitish, err = regexp.Compile(request.Source.TargetCommitish)
+		if err != nil {
+			return []Version{}, fmt.Errorf("invalid target_commitish: %w", err)
+		}
+	}
+
+	releases, err := c.github.ListReleases(ctx)
 	if err != nil {
 		return []Version{}, err
 	}
@@ -59,14 +283,14 @@ func (c *CheckCommand) Run(request CheckRequest) ([]Version, error) {
 
 	var filteredReleases []*github.RepositoryRelease
 
-	versionParser, err := newVersionParser(request.Source.TagFilter)
+	versionParser, err := newVersionParser(request.Source)
 	if err != nil {
 		return []Version{}, err
 	}
 
-	var constraint *semver.Constraints
+	var constraint versionConstraint
 	if request.Source.SemverConstraint != "" {
-		constraint, err = semver.NewConstraint(request.Source.SemverConstraint)
+		constraint, err = versionParser.scheme.constraint(request.Source.SemverConstraint)
 		if err != nil {
 			return []Version{}, err
 		}
@@ -84,18 +308,25 @@ func (c *CheckCommand) Run(request CheckRequest) ([]Version, error) {
 			continue
 		}
 
-		if constraint != nil {
-			if release.TagName == nil {
-				// Release has no tag, so certainly isn't a valid semver
+		if !contentFilter.matches(release) {
+			continue
+		}
+
+		if minAge > 0 {
+			// Releases of unknown age are not known to be old enough.
+			timestamp := getTimestamp(release)
+			if timestamp.IsZero() || timestamp.After(publishedBefore) {
 				continue
 			}
-			version, err := semver.NewVersion(versionParser.parse(*release.TagName))
-			if err != nil {
-				// Release is not tagged with a valid semver
+		}
+
+		if constraint != nil {
+			if release.TagName == nil {
+				// Release has no tag, so certainly isn't a valid version
 				continue
 			}
-			if !constraint.Check(version) {
-				// Valid semver, but does not satisfy constraint
+			if !constraint.check(versionParser.parse(*release.TagName)) {
+				// Not a valid version, or does not satisfy constraint
 				continue
 			}
 		}
@@ -119,7 +350,7 @@ func (c *CheckCommand) Run(request CheckRequest) ([]Version, error) {
 			if release.TagName == nil {
 				continue
 			}
-			if _, err := version.NewVersionFromString(versionParser.parse(*release.TagName)); err != nil {
+			if _, err := versionParser.version(*release.TagName); err != nil {
 				continue
 			}
 		}
@@ -141,58 +372,63 @@ func (c *CheckCommand) Run(request CheckRequest) ([]Version, error) {
 		SortByVersion(filteredReleases, &versionParser)
 	}
 
-	// If request has no version, output the latest release
+	// Find first release equal or later than the current version
 
-	latestRelease := filteredReleases[len(filteredReleases)-1]
+	firstIncludedReleaseIndex := len(filteredReleases)
 
-	if (request.Version == Version{}) {
-		return []Version{
-			versionFromRelease(latestRelease),
-		}, nil
+	if (request.Version != Version{}) {
+		if orderByTime {
+			// Only search if request has a timestamp
+			if !request.Version.Timestamp.IsZero() {
+				firstIncludedReleaseIndex = sort.Search(len(filteredReleases), func(i int) bool {
+					release := filteredReleases[i]
+					return !getTimestamp(release).Before(request.Version.Timestamp)
+				})
+			}
+		} else {
+			requestVersion, err := versionParser.version(request.Version.Tag)
+			if err == nil {
+				firstIncludedReleaseIndex = sort.Search(len(filteredReleases), func(i int) bool {
+					release := filteredReleases[i]
+					releaseVersion, err := versionParser.version(*release.TagName)
+					if err != nil {
+						return false
+					}
+					return releaseVersion.compare(requestVersion) >= 0
+				})
+			}
+		}
 	}
 
-	// Find first release equal or later than the current version
+	// Output all releases equal or later than the current version,
+	// or just the latest release if there are no such releases. Filters
+	// that cost a request per release are only applied to the releases
+	// that would be output.
 
-	var firstIncludedReleaseIndex int = -1
+	outputVersions := []Version{}
 
-	if orderByTime {
-		// Only search if request has a timestamp
-		if !request.Version.Timestamp.IsZero() {
-			firstIncludedReleaseIndex = sort.Search(len(filteredReleases), func(i int) bool {
-				release := filteredReleases[i]
-				return !getTimestamp(release).Before(request.Version.Timestamp)
-			})
+	for _, release := range filteredReleases[firstIncludedReleaseIndex:] {
+		accepted, err := c.accepts(ctx, release, filter)
+		if err != nil {
+			return []Version{}, err
 		}
-	} else {
-		requestVersion, err := version.NewVersionFromString(versionParser.parse(request.Version.Tag))
-		if err == nil {
-			firstIncludedReleaseIndex = sort.Search(len(filteredReleases), func(i int) bool {
-				release := filteredReleases[i]
-				releaseVersion, err := version.NewVersionFromString(versionParser.parse(*release.TagName))
-				if err != nil {
-					return false
-				}
-				return !releaseVersion.IsLt(requestVersion)
-			})
+		if accepted {
+			outputVersions = append(outputVersions, versionFromRelease(release))
 		}
 	}
 
-	// Output all releases equal or later than the current version,
-	// or just the latest release if there are no such releases.
-
-	outputVersions := []Version{}
+	if len(outputVersions) > 0 {
+		return outputVersions, nil
+	}
 
-	if firstIncludedReleaseIndex >= 0 && firstIncludedReleaseIndex < len(filteredReleases) {
-		// Found first release >= current version, so output this and all the following release versions
-		for i := firstIncludedReleaseIndex; i < len(filteredReleases); i++ {
-			outputVersions = append(outputVersions, versionFromRelease(filteredReleases[i]))
+	for i := firstIncludedReleaseIndex - 1; i >= 0; i-- {
+		accepted, err := c.accepts(ctx, filteredReleases[i], filter)
+		if err != nil {
+			return []Version{}, err
+		}
+		if accepted {
+			return []Version{versionFromRelease(filteredReleases[i])}, nil
 		}
-	} else {
-		// No release >= current version, so output the latest release version
-		outputVersions = append(
-			outputVersions,
-			versionFromRelease(filteredReleases[len(filteredReleases)-1]),
-		)
 	}
 
 	return outputVersions, nil
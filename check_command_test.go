@@ -1,10 +1,14 @@
 package resource_test
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 
 	resource "github.com/concourse/github-release-resource"
 	"github.com/concourse/github-release-resource/fakes"
@@ -36,7 +40,7 @@ var _ = Describe("Check Command", func() {
 			})
 
 			It("returns no versions", func() {
-				versions, err := command.Run(resource.CheckRequest{})
+				versions, err := command.Run(context.Background(), resource.CheckRequest{})
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(versions).Should(BeEmpty())
 			})
@@ -51,7 +55,7 @@ var _ = Describe("Check Command", func() {
 
 			Context("and releases are ordered by version", func() {
 				It("returns no versions", func() {
-					versions, err := command.Run(resource.CheckRequest{})
+					versions, err := command.Run(context.Background(), resource.CheckRequest{})
 					Ω(err).ShouldNot(HaveOccurred())
 					Ω(versions).Should(BeEmpty())
 				})
@@ -59,7 +63,7 @@ var _ = Describe("Check Command", func() {
 
 			Context("and releases are ordered by time", func() {
 				It("returns no versions", func() {
-					versions, err := command.Run(resource.CheckRequest{
+					versions, err := command.Run(context.Background(), resource.CheckRequest{
 						Source: resource.Source{OrderBy: "time"},
 					})
 					Ω(err).ShouldNot(HaveOccurred())
@@ -81,7 +85,7 @@ var _ = Describe("Check Command", func() {
 				It("outputs the most recent version only", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{})
+					response, err := command.Run(context.Background(), resource.CheckRequest{})
 					Ω(err).ShouldNot(HaveOccurred())
 
 					Ω(response).Should(HaveLen(1))
@@ -93,7 +97,7 @@ var _ = Describe("Check Command", func() {
 				It("outputs the most recent time only", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Source: resource.Source{OrderBy: "time"},
 					})
 					Ω(err).ShouldNot(HaveOccurred())
@@ -116,7 +120,7 @@ var _ = Describe("Check Command", func() {
 				It("keeps only those versions matching the constraint", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Source: resource.Source{SemverConstraint: "0.1.x"},
 					})
 					Ω(err).ShouldNot(HaveOccurred())
@@ -138,7 +142,7 @@ var _ = Describe("Check Command", func() {
 					It("uses the filter", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Source: resource.Source{
 								SemverConstraint: "0.1.x",
 								TagFilter:        "foo-(.*)",
@@ -161,7 +165,7 @@ var _ = Describe("Check Command", func() {
 			})
 
 			It("returns no versions", func() {
-				versions, err := command.Run(resource.CheckRequest{})
+				versions, err := command.Run(context.Background(), resource.CheckRequest{})
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(versions).Should(BeEmpty())
 			})
@@ -181,7 +185,7 @@ var _ = Describe("Check Command", func() {
 				It("returns all of the versions that are newer", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Version: resource.Version{
 							Tag: "package-0.1.3",
 						},
@@ -209,7 +213,7 @@ var _ = Describe("Check Command", func() {
 				It("returns the current version if it is also the latest", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Version: resource.Version{
 							Tag: "0.4.0",
 						},
@@ -224,7 +228,7 @@ var _ = Describe("Check Command", func() {
 				It("returns all of the versions that are newer", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Version: resource.Version{
 							Tag: "v0.1.3",
 						},
@@ -241,7 +245,7 @@ var _ = Describe("Check Command", func() {
 				It("returns all newer versions even when current version not found", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Version: resource.Version{
 							Tag: "v0.1.4-beta",
 						},
@@ -257,7 +261,7 @@ var _ = Describe("Check Command", func() {
 				It("returns the latest version if the current version is not found", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Version: resource.Version{
 							Tag: "v3.4.5",
 						},
@@ -278,7 +282,7 @@ var _ = Describe("Check Command", func() {
 					It("combines them with the semver versions in a reasonable order", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{
 								Tag: "v0.1.3",
 							},
@@ -307,7 +311,7 @@ var _ = Describe("Check Command", func() {
 				It("returns all of the versions that are newer, and not a draft", func() {
 					command := resource.NewCheckCommand(githubClient)
 
-					response, err := command.Run(resource.CheckRequest{
+					response, err := command.Run(context.Background(), resource.CheckRequest{
 						Version: resource.Version{
 							Tag: "v0.1.3",
 						},
@@ -337,7 +341,7 @@ var _ = Describe("Check Command", func() {
 					It("returns all of the versions that are newer, and only pre relases", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{ID: "3", Tag: "0.4.1-rc.9"},
 							Source:  resource.Source{Drafts: false, PreRelease: true, Release: false},
 						})
@@ -352,7 +356,7 @@ var _ = Describe("Check Command", func() {
 					It("returns the latest prerelease version if the current version is not found", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{ID: "5"},
 							Source:  resource.Source{Drafts: false, PreRelease: true, Release: false},
 						})
@@ -384,7 +388,7 @@ var _ = Describe("Check Command", func() {
 					It("returns all of the versions that are newer, and are release and prerealse", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{Tag: "0.4.0"},
 							Source:  resource.Source{Drafts: false, PreRelease: true, Release: true},
 						})
@@ -402,7 +406,7 @@ var _ = Describe("Check Command", func() {
 					It("returns the latest release version if the current version is not found", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{ID: "5"},
 							Source:  resource.Source{Drafts: false, PreRelease: true, Release: true},
 						})
@@ -432,7 +436,7 @@ var _ = Describe("Check Command", func() {
 					It("returns all of the versions that are newer, and are release and prerelease", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{Tag: "0.4.0"},
 							Source:  resource.Source{Drafts: false, PreRelease: true, Release: true},
 						})
@@ -451,7 +455,7 @@ var _ = Describe("Check Command", func() {
 					It("returns the latest prerelease version if the current version is not found", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{ID: "5"},
 							Source:  resource.Source{Drafts: false, PreRelease: true, Release: true},
 						})
@@ -479,7 +483,7 @@ var _ = Describe("Check Command", func() {
 					It("returns all of the versions that are newer, and only draft", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{Tag: "v0.1.3"},
 							Source:  resource.Source{Drafts: true},
 						})
@@ -494,7 +498,7 @@ var _ = Describe("Check Command", func() {
 					It("returns all newer draft versions even if current version is not found", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{Tag: "v0.1.2"},
 							Source:  resource.Source{Drafts: true},
 						})
@@ -518,7 +522,7 @@ var _ = Describe("Check Command", func() {
 					It("returns all of the releases with semver resources", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{},
 							Source:  resource.Source{Drafts: true},
 						})
@@ -541,7 +545,7 @@ var _ = Describe("Check Command", func() {
 					It("returns all of the releases with semver resources", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{},
 							Source:  resource.Source{Drafts: true, PreRelease: false},
 						})
@@ -567,7 +571,7 @@ var _ = Describe("Check Command", func() {
 					It("returns releases with newer created time", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: newVersionWithTimestamp(3, "v0.1.3", 3),
 							Source:  resource.Source{OrderBy: "time"},
 						})
@@ -591,7 +595,7 @@ var _ = Describe("Check Command", func() {
 					It("returns releases with newer published time", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: newVersionWithTimestamp(3, "v0.1.3", 3),
 							Source:  resource.Source{OrderBy: "time"},
 						})
@@ -615,7 +619,7 @@ var _ = Describe("Check Command", func() {
 					It("returns releases with newer published time", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: newVersionWithTimestamp(2, "v0.2.1", 4),
 							Source:  resource.Source{OrderBy: "time"},
 						})
@@ -629,7 +633,7 @@ var _ = Describe("Check Command", func() {
 					It("returns releases with newer published time even when current version not found", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: newVersionWithTimestamp(9, "v1.0.0", 3),
 							Source:  resource.Source{OrderBy: "time"},
 						})
@@ -643,7 +647,7 @@ var _ = Describe("Check Command", func() {
 					It("returns release with latest published time when request has no timestamp", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: resource.Version{ID: "2", Tag: "v0.2.1"},
 							Source:  resource.Source{OrderBy: "time"},
 						})
@@ -667,7 +671,7 @@ var _ = Describe("Check Command", func() {
 					It("returns empty list", func() {
 						command := resource.NewCheckCommand(githubClient)
 
-						response, err := command.Run(resource.CheckRequest{
+						response, err := command.Run(context.Background(), resource.CheckRequest{
 							Version: newVersionWithTimestamp(2, "v0.2.1", 3),
 							Source:  resource.Source{OrderBy: "time"},
 						})
@@ -678,4 +682,511 @@ var _ = Describe("Check Command", func() {
 			})
 		})
 	})
+
+	Context("when there is a version scheme", func() {
+		checkTags := func(source resource.Source, tags []string, since string) []string {
+			returnedReleases = []*github.RepositoryRelease{}
+			for i, tag := range tags {
+				returnedReleases = append(returnedReleases, newRepositoryRelease(i+1, tag))
+			}
+			githubClient.ListReleasesReturns(returnedReleases, nil)
+
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  source,
+				Version: resource.Version{Tag: since},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			versions := []string{}
+			for _, version := range response {
+				versions = append(versions, version.Tag)
+			}
+			return versions
+		}
+
+		DescribeTable("orders the versions by the scheme",
+			func(source resource.Source, tags []string, since string, expected []string) {
+				Ω(checkTags(source, tags, since)).Should(Equal(expected))
+			},
+			Entry("semver",
+				resource.Source{VersionScheme: "semver"},
+				[]string{"v1.10.0", "v1.2.0", "v1.10.0-rc.1", "not-a-version", "v1.9.3"},
+				"v1.2.0",
+				[]string{"v1.2.0", "v1.9.3", "v1.10.0-rc.1", "v1.10.0"},
+			),
+			Entry("semi-semantic",
+				resource.Source{VersionScheme: "semi-semantic"},
+				[]string{"v1.10", "v1.2.0.5", "v1.2"},
+				"v1.2",
+				[]string{"v1.2", "v1.2.0.5", "v1.10"},
+			),
+			Entry("calver with a date",
+				resource.Source{VersionScheme: "calver", CalVerFormat: "YYYY.0M.0D", TagFilter: "(.*)"},
+				[]string{"2024.05.17", "2023.12.01", "2024.11.02", "2024.13.01", "2024.5.17"},
+				"2023.12.01",
+				[]string{"2023.12.01", "2024.05.17", "2024.11.02"},
+			),
+			Entry("calver with a micro and modifier",
+				resource.Source{VersionScheme: "calver", CalVerFormat: "YY.0M.MICRO-MODIFIER"},
+				[]string{"v24.04.1-build10", "v24.04.1", "v24.04.1-build3", "v24.10.0", "v23.10.12"},
+				"v23.10.12",
+				[]string{"v23.10.12", "v24.04.1-build3", "v24.04.1-build10", "v24.04.1", "v24.10.0"},
+			),
+			Entry("PEP 440",
+				resource.Source{VersionScheme: "pep440"},
+				[]string{"1.0.post1", "1.0", "1.0rc1", "1.0.dev2", "1.0a1", "1!0.1", "1.0b2.post3", "1.0+local.7"},
+				"1.0.dev2",
+				[]string{"1.0.dev2", "1.0a1", "1.0b2.post3", "1.0rc1", "1.0", "1.0+local.7", "1.0.post1", "1!0.1"},
+			),
+			Entry("Debian",
+				resource.Source{VersionScheme: "debian", TagFilter: "debian/(.*)"},
+				[]string{"debian/1.0-1", "debian/1.0~rc1-1", "debian/1:0.9-1", "debian/1.0-1ubuntu1", "debian/1.0+dfsg-1"},
+				"debian/1.0~rc1-1",
+				[]string{"debian/1.0~rc1-1", "debian/1.0-1", "debian/1.0-1ubuntu1", "debian/1.0+dfsg-1", "debian/1:0.9-1"},
+			),
+			Entry("numeric",
+				resource.Source{VersionScheme: "numeric", TagFilter: "build-(.*)"},
+				[]string{"build-100", "build-99", "build-1000", "build-abc"},
+				"build-99",
+				[]string{"build-99", "build-100", "build-1000"},
+			),
+		)
+
+		DescribeTable("evaluates the constraint with the scheme",
+			func(source resource.Source, tags []string, expected []string) {
+				Ω(checkTags(source, tags, expected[0])).Should(Equal(expected))
+			},
+			Entry("calver",
+				resource.Source{VersionScheme: "calver", CalVerFormat: "YYYY.0M.0D", SemverConstraint: ">= 2024.01.01, < 2024.06.01"},
+				[]string{"2024.05.17", "2023.12.01", "2024.11.02", "2024.02.29"},
+				[]string{"2024.02.29", "2024.05.17"},
+			),
+			Entry("PEP 440 with alternatives",
+				resource.Source{VersionScheme: "pep440", SemverConstraint: "< 1.0 || == 2.0"},
+				[]string{"0.9", "1.0", "2.0.0", "2.1"},
+				[]string{"0.9", "2.0.0"},
+			),
+			Entry("semver",
+				resource.Source{VersionScheme: "semver", SemverConstraint: "~1.2"},
+				[]string{"1.2.0", "1.3.0", "1.2.7"},
+				[]string{"1.2.0", "1.2.7"},
+			),
+		)
+
+		DescribeTable("rejects invalid configuration",
+			func(source resource.Source, message string) {
+				githubClient.ListReleasesReturns([]*github.RepositoryRelease{newRepositoryRelease(1, "v1.0.0")}, nil)
+
+				_, err := command.Run(context.Background(), resource.CheckRequest{Source: source})
+				Ω(err).Should(MatchError(message))
+			},
+			Entry("an unknown scheme",
+				resource.Source{VersionScheme: "roman"},
+				"unknown version_scheme 'roman': must be one of semver, semi-semantic, calver, pep440, debian or numeric",
+			),
+			Entry("calver without a format",
+				resource.Source{VersionScheme: "calver"},
+				"the calver version_scheme requires calver_format",
+			),
+			Entry("a format without calver",
+				resource.Source{CalVerFormat: "YYYY.0M"},
+				"calver_format requires the calver version_scheme",
+			),
+			Entry("a modifier that is not last",
+				resource.Source{VersionScheme: "calver", CalVerFormat: "YYYY-MODIFIER.MICRO"},
+				"invalid calver_format 'YYYY-MODIFIER.MICRO': MODIFIER must come last",
+			),
+			Entry("a constraint that does not parse with the scheme",
+				resource.Source{VersionScheme: "numeric", SemverConstraint: ">= 1.x"},
+				"invalid constraint '>= 1.x': invalid numeric version '1.x'",
+			),
+		)
+	})
+
+	Context("when there are required assets", func() {
+		asset := func(name, state string) *github.ReleaseAsset {
+			return &github.ReleaseAsset{Name: github.String(name), State: github.String(state)}
+		}
+
+		BeforeEach(func() {
+			assets := map[int64][]*github.ReleaseAsset{
+				1: {
+					asset("tool-linux-amd64.tgz", "uploaded"),
+					asset("tool-darwin-arm64.tgz", "uploaded"),
+				},
+				2: {
+					asset("tool-linux-amd64.tgz", "uploaded"),
+					asset("tool-darwin-arm64.tgz", "starter"),
+				},
+				3: {
+					{Name: github.String("tool-linux-amd64.tgz")},
+					{Name: github.String("tool-darwin-arm64.tgz")},
+				},
+			}
+
+			githubClient.ListReleaseAssetsStub = func(_ context.Context, release github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+				return assets[release.GetID()], nil
+			}
+
+			returnedReleases = []*github.RepositoryRelease{
+				newRepositoryRelease(1, "v0.1.0"),
+				newRepositoryRelease(2, "v0.2.0"),
+				newRepositoryRelease(3, "v0.0.9"),
+				newRepositoryRelease(4, "v0.3.0"),
+			}
+		})
+
+		It("only emits releases with an uploaded asset matching each glob", func() {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  resource.Source{RequiredAssets: []string{"*-linux-*", "*-darwin-*"}},
+				Version: resource.Version{Tag: "v0.0.9"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{ID: "1", Tag: "v0.1.0"},
+			}))
+		})
+
+		It("emits the latest release with the assets on the first check", func() {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{RequiredAssets: []string{"*-linux-*"}},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{ID: "2", Tag: "v0.2.0"},
+			}))
+		})
+
+		It("only lists the assets of releases listed without them that it would emit", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{RequiredAssets: []string{"*-linux-*"}},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(2))
+			_, release := githubClient.ListReleaseAssetsArgsForCall(0)
+			Ω(release.GetID()).Should(Equal(int64(4)))
+			_, release = githubClient.ListReleaseAssetsArgsForCall(1)
+			Ω(release.GetID()).Should(Equal(int64(2)))
+		})
+
+		It("uses the assets listed along with the releases", func() {
+			for _, release := range returnedReleases {
+				release.Assets = []*github.ReleaseAsset{}
+			}
+			returnedReleases[1].Assets = []*github.ReleaseAsset{
+				asset("tool-linux-amd64.tgz", "uploaded"),
+			}
+
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{RequiredAssets: []string{"*-linux-*"}},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{ID: "2", Tag: "v0.2.0"},
+			}))
+			Ω(githubClient.ListReleaseAssetsCallCount()).Should(BeZero())
+		})
+
+		It("fails when the assets cannot be listed", func() {
+			githubClient.ListReleaseAssetsReturns(nil, errors.New("disaster"))
+			githubClient.ListReleaseAssetsStub = nil
+
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{RequiredAssets: []string{"*-linux-*"}},
+			})
+			Ω(err).Should(MatchError("disaster"))
+		})
+
+		It("rejects invalid globs before listing releases", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{RequiredAssets: []string{"tool-["}},
+			})
+			Ω(err).Should(MatchError("invalid required_assets glob 'tool-['"))
+			Ω(githubClient.ListReleasesCallCount()).Should(BeZero())
+		})
+	})
+
+	Context("when there is a minimum age", func() {
+		BeforeEach(func() {
+			command.SetClock(func() time.Time {
+				return exampleTimeStamp(10).Add(12 * time.Hour)
+			})
+
+			returnedReleases = []*github.RepositoryRelease{
+				newRepositoryReleaseWithPublishedTime(1, "v0.1.0", 1),
+				newRepositoryReleaseWithCreatedAndPublishedTime(2, "v0.2.0", 2, 8),
+				newRepositoryReleaseWithPublishedTime(3, "v0.3.0", 9),
+				newRepositoryReleaseWithCreatedTime(4, "v0.4.0", 10),
+				newRepositoryRelease(5, "v0.0.9"),
+			}
+		})
+
+		It("ignores releases published more recently when ordering by version", func() {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  resource.Source{MinAge: "48h"},
+				Version: resource.Version{Tag: "v0.1.0"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				newVersionWithTimestamp(1, "v0.1.0", 1),
+				newVersionWithTimestamp(2, "v0.2.0", 8),
+			}))
+		})
+
+		It("ignores releases published more recently when ordering by time", func() {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{MinAge: "24h", OrderBy: "time"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				newVersionWithTimestamp(3, "v0.3.0", 9),
+			}))
+		})
+
+		It("rejects an invalid duration", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{MinAge: "a week"},
+			})
+			Ω(err).Should(MatchError(ContainSubstring("invalid min_age")))
+		})
+	})
+
+	Context("when filtering by target commitish", func() {
+		BeforeEach(func() {
+			onBranch := func(id int, tag, commitish string) *github.RepositoryRelease {
+				release := newRepositoryRelease(id, tag)
+				release.TargetCommitish = github.String(commitish)
+				return release
+			}
+
+			returnedReleases = []*github.RepositoryRelease{
+				onBranch(1, "v1.0.0", "release/1.x"),
+				onBranch(2, "v2.0.0", "main"),
+				onBranch(3, "v1.1.0", "release/1.x"),
+				newRepositoryRelease(4, "v1.2.0"),
+			}
+
+			githubClient.GetReleaseReturns(onBranch(4, "v1.2.0", "release/1.x"), nil)
+		})
+
+		It("only emits releases whose target commitish matches", func() {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  resource.Source{TargetCommitish: `^release/1\.x$`},
+				Version: resource.Version{Tag: "v1.0.0"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{ID: "1", Tag: "v1.0.0"},
+				{ID: "3", Tag: "v1.1.0"},
+				{ID: "4", Tag: "v1.2.0"},
+			}))
+		})
+
+		It("fetches the target commitish of releases listed without it", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{TargetCommitish: `^release/`},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.GetReleaseCallCount()).Should(Equal(1))
+			_, id := githubClient.GetReleaseArgsForCall(0)
+			Ω(id).Should(Equal(4))
+		})
+
+		It("does not fetch releases older than the current version", func() {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  resource.Source{TargetCommitish: `^main$`},
+				Version: resource.Version{Tag: "v2.0.0"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{ID: "2", Tag: "v2.0.0"},
+			}))
+			Ω(githubClient.GetReleaseCallCount()).Should(BeZero())
+		})
+
+		It("rejects an invalid regular expression", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{TargetCommitish: "release/(1"},
+			})
+			Ω(err).Should(MatchError(ContainSubstring("invalid target_commitish")))
+		})
+	})
+
+	Context("when the tagged commit must be reachable from a branch", func() {
+		var commits map[string]string
+
+		BeforeEach(func() {
+			returnedReleases = []*github.RepositoryRelease{
+				newRepositoryRelease(1, "v1.0.0"),
+				newRepositoryRelease(2, "v2.0.0"),
+				newRepositoryRelease(3, "v1.1.0"),
+				newRepositoryRelease(4, "v1.1.1"),
+			}
+
+			commits = map[string]string{
+				"v1.0.0": "sha-1",
+				"v2.0.0": "sha-2",
+				"v1.1.0": "sha-3",
+				"v1.1.1": "sha-3",
+			}
+			githubClient.ResolveTagToCommitSHAStub = func(_ context.Context, tag string) (string, error) {
+				sha, found := commits[tag]
+				if !found {
+					return "", errors.New("404 Not Found")
+				}
+				return sha, nil
+			}
+			githubClient.IsCommitReachableStub = func(_ context.Context, sha, branch string) (bool, error) {
+				return sha != "sha-2" && branch == "release/1.x", nil
+			}
+		})
+
+		It("only emits releases tagged on a commit in the history of the branch", func() {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  resource.Source{ReachableFrom: "release/1.x"},
+				Version: resource.Version{Tag: "v1.0.0"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{ID: "1", Tag: "v1.0.0"},
+				{ID: "3", Tag: "v1.1.0"},
+				{ID: "4", Tag: "v1.1.1"},
+			}))
+		})
+
+		It("compares each commit with the branch once", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  resource.Source{ReachableFrom: "release/1.x"},
+				Version: resource.Version{Tag: "v1.0.0"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.IsCommitReachableCallCount()).Should(Equal(3))
+		})
+
+		It("stops comparing once it finds the latest release on the first check", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{ReachableFrom: "release/1.x"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.IsCommitReachableCallCount()).Should(Equal(2))
+		})
+
+		It("skips drafts whose tag does not exist yet", func() {
+			returnedReleases = append(returnedReleases, newDraftRepositoryRelease(5, "v1.2.0"))
+			githubClient.ListReleasesReturns(returnedReleases, nil)
+
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{ReachableFrom: "release/1.x", Drafts: true},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(response).Should(BeEmpty())
+		})
+
+		It("fails if the tag of a release cannot be resolved", func() {
+			delete(commits, "v2.0.0")
+
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{ReachableFrom: "release/1.x"},
+			})
+			Ω(err).Should(MatchError("404 Not Found"))
+		})
+	})
+
+	Context("when filtering by name, body and author", func() {
+		BeforeEach(func() {
+			release := func(id int, tag, name, body, author string) *github.RepositoryRelease {
+				r := newRepositoryRelease(id, tag)
+				r.Name = github.String(name)
+				r.Body = github.String(body)
+				r.Author = &github.User{Login: github.String(author)}
+				return r
+			}
+
+			returnedReleases = []*github.RepositoryRelease{
+				release(1, "v1.0.0", "Server 1.0.0", "Stable.", "octocat"),
+				release(2, "v1.1.0", "Server 1.1.0", "DO NOT USE: broken migration.", "octocat"),
+				release(3, "v1.2.0", "CLI 1.2.0", "Stable.", "octocat"),
+				release(4, "v1.3.0", "Server 1.3.0", "Stable, with checksums.", "github-actions[bot]"),
+				release(5, "v1.4.0", "Server 1.4.0", "Stable.", "mallory"),
+				newRepositoryRelease(6, "v1.5.0"),
+			}
+		})
+
+		check := func(source resource.Source) []resource.Version {
+			response, err := command.Run(context.Background(), resource.CheckRequest{
+				Source:  source,
+				Version: resource.Version{Tag: "v1.0.0"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			return response
+		}
+
+		It("keeps releases whose name matches name_filter", func() {
+			Ω(check(resource.Source{NameFilter: "^Server "})).Should(Equal([]resource.Version{
+				{ID: "1", Tag: "v1.0.0"},
+				{ID: "2", Tag: "v1.1.0"},
+				{ID: "4", Tag: "v1.3.0"},
+				{ID: "5", Tag: "v1.4.0"},
+			}))
+		})
+
+		It("keeps releases whose body matches body_filter", func() {
+			Ω(check(resource.Source{BodyFilter: "(?i)checksums"})).Should(Equal([]resource.Version{
+				{ID: "4", Tag: "v1.3.0"},
+			}))
+		})
+
+		It("skips releases whose body contains one of body_excludes", func() {
+			Ω(check(resource.Source{BodyExcludes: []string{"DO NOT USE", "YANKED"}})).Should(Equal([]resource.Version{
+				{ID: "1", Tag: "v1.0.0"},
+				{ID: "3", Tag: "v1.2.0"},
+				{ID: "4", Tag: "v1.3.0"},
+				{ID: "5", Tag: "v1.4.0"},
+				{ID: "6", Tag: "v1.5.0"},
+			}))
+		})
+
+		It("keeps releases by one of the authors, regardless of case and bot suffix", func() {
+			Ω(check(resource.Source{Authors: []string{"OctoCat", "github-actions"}})).Should(Equal([]resource.Version{
+				{ID: "1", Tag: "v1.0.0"},
+				{ID: "2", Tag: "v1.1.0"},
+				{ID: "3", Tag: "v1.2.0"},
+				{ID: "4", Tag: "v1.3.0"},
+			}))
+		})
+
+		It("combines the filters", func() {
+			Ω(check(resource.Source{
+				NameFilter:   "^Server ",
+				BodyExcludes: []string{"DO NOT USE"},
+				Authors:      []string{"octocat"},
+			})).Should(Equal([]resource.Version{
+				{ID: "1", Tag: "v1.0.0"},
+			}))
+		})
+
+		It("rejects invalid regular expressions", func() {
+			_, err := command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{NameFilter: "Server ("},
+			})
+			Ω(err).Should(MatchError(ContainSubstring("invalid name_filter")))
+
+			_, err = command.Run(context.Background(), resource.CheckRequest{
+				Source: resource.Source{BodyFilter: "[stable"},
+			})
+			Ω(err).Should(MatchError(ContainSubstring("invalid body_filter")))
+		})
+	})
 })
@@ -0,0 +1,288 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type checksum struct {
+	algorithm string
+	digest    string
+}
+
+// checksums maps asset names to their expected checksums.
+type checksums map[string][]checksum
+
+var (
+	// SHA256 (file.tgz) = 2cf24dba...
+	bsdChecksumLine = regexp.MustCompile(`^([A-Za-z0-9-]+) \((.+)\) ?= ?([0-9a-fA-F]+)$`)
+
+	// 2cf24dba...  file.tgz (GNU coreutils and goreleaser), or
+	// 2cf24dba... *file.tgz (GNU coreutils binary mode)
+	gnuChecksumLine = regexp.MustCompile(`^([0-9a-fA-F]+) [ *]?(.+)$`)
+
+	// 2cf24dba... on its own, as in per-file file.tgz.sha256 assets
+	bareChecksumLine = regexp.MustCompile(`^([0-9a-fA-F]+)$`)
+)
+
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm '%s'", algorithm)
+	}
+}
+
+func algorithmForDigest(digest string) (string, error) {
+	switch len(digest) {
+	case md5.Size * 2:
+		return "md5", nil
+	case sha1.Size * 2:
+		return "sha1", nil
+	case sha256.Size * 2:
+		return "sha256", nil
+	case sha512.Size * 2:
+		return "sha512", nil
+	default:
+		return "", fmt.Errorf("could not determine checksum algorithm for digest '%s'", digest)
+	}
+}
+
+// parseChecksums reads a checksum file in GNU coreutils, BSD or goreleaser
+// format. Per-file checksum files that only contain a digest are attributed
+// to the asset named like the checksum file without its extension.
+func parseChecksums(name string, content []byte) (checksums, error) {
+	sums := checksums{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var algorithm, file, digest string
+		if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+			algorithm = strings.ReplaceAll(strings.ToLower(m[1]), "-", "")
+			file, digest = m[2], m[3]
+		} else if m := gnuChecksumLine.FindStringSubmatch(line); m != nil {
+			digest, file = m[1], m[2]
+		} else if m := bareChecksumLine.FindStringSubmatch(line); m != nil {
+			digest = m[1]
+			file = strings.TrimSuffix(name, path.Ext(name))
+		} else {
+			return nil, fmt.Errorf("could not parse line in checksum file '%s': %s", name, line)
+		}
+
+		if algorithm == "" {
+			var err error
+			algorithm, err = algorithmForDigest(digest)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		file = path.Base(file)
+		sums[file] = append(sums[file], checksum{
+			algorithm: algorithm,
+			digest:    strings.ToLower(digest),
+		})
+	}
+
+	return sums, scanner.Err()
+}
+
+// digester computes digests for several algorithms over the same stream.
+type digester struct {
+	hashes map[string]hash.Hash
+}
+
+func newDigester(algorithms ...string) (*digester, error) {
+	hashes := map[string]hash.Hash{}
+	for _, algorithm := range algorithms {
+		if _, found := hashes[algorithm]; found {
+			continue
+		}
+
+		h, err := newHash(algorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		hashes[algorithm] = h
+	}
+
+	return &digester{hashes: hashes}, nil
+}
+
+func (d *digester) Write(p []byte) (int, error) {
+	for _, h := range d.hashes {
+		h.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// digests returns the hex encoded digest for each algorithm.
+func (d *digester) digests() map[string]string {
+	digests := map[string]string{}
+	for algorithm, h := range d.hashes {
+		digests[algorithm] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	return digests
+}
+
+// fileDigests computes the hex encoded digest of the file for each algorithm
+// in a single pass.
+func fileDigests(filePath string, algorithms ...string) (map[string]string, error) {
+	d, err := newDigester(algorithms...)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(d, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.digests(), nil
+}
+
+// verifyChecksums checks the file against every expected checksum.
+func verifyChecksums(name, filePath string, expected []checksum) error {
+	var algorithms []string
+	for _, c := range expected {
+		algorithms = append(algorithms, c.algorithm)
+	}
+
+	digests, err := fileDigests(filePath, algorithms...)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range expected {
+		if digests[c.algorithm] != c.digest {
+			return fmt.Errorf("%s checksum mismatch for asset '%s': expected %s, got %s", c.algorithm, name, c.digest, digests[c.algorithm])
+		}
+	}
+
+	return nil
+}
+
+// assetDigest returns the checksum GitHub computed for the asset, if any.
+func assetDigest(digest string) (checksum, bool) {
+	algorithm, value, found := strings.Cut(digest, ":")
+	if !found || value == "" {
+		return checksum{}, false
+	}
+
+	return checksum{
+		algorithm: strings.ToLower(algorithm),
+		digest:    strings.ToLower(value),
+	}, true
+}
+
+// assetDigests holds the digests computed for an uploaded asset.
+type assetDigests struct {
+	name    string
+	digests map[string]string
+}
+
+// checksumManifest is a checksum file generated over the uploaded assets.
+type checksumManifest struct {
+	name       string
+	format     string
+	algorithms []string
+}
+
+// newChecksumManifests plans the manifests to generate. GNU coreutils
+// manifests cannot mix algorithms, so each algorithm gets its own file;
+// BSD manifests tag every line and hold all of them.
+func newChecksumManifests(format, name string, algorithms []string) ([]checksumManifest, error) {
+	for _, algorithm := range algorithms {
+		_, err := newHash(algorithm)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var manifests []checksumManifest
+	switch format {
+	case "", "gnu":
+		for _, algorithm := range algorithms {
+			manifests = append(manifests, checksumManifest{
+				name:       strings.ToUpper(algorithm) + "SUMS",
+				format:     "gnu",
+				algorithms: []string{algorithm},
+			})
+		}
+	case "bsd":
+		manifests = append(manifests, checksumManifest{
+			name:       "CHECKSUMS",
+			format:     "bsd",
+			algorithms: algorithms,
+		})
+	case "goreleaser":
+		if len(algorithms) > 1 {
+			return nil, errors.New("checksum_format 'goreleaser' supports a single checksum algorithm")
+		}
+		manifests = append(manifests, checksumManifest{
+			name:       "checksums.txt",
+			format:     "gnu",
+			algorithms: algorithms,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported checksum_format '%s'", format)
+	}
+
+	if name != "" {
+		if len(manifests) > 1 {
+			return nil, errors.New("checksum_file can only be set when a single checksum manifest is generated")
+		}
+		manifests[0].name = name
+	}
+
+	return manifests, nil
+}
+
+func (m checksumManifest) render(assets []assetDigests) []byte {
+	var buf bytes.Buffer
+	for _, algorithm := range m.algorithms {
+		for _, asset := range assets {
+			if m.format == "bsd" {
+				fmt.Fprintf(&buf, "%s (%s) = %s\n", strings.ToUpper(algorithm), asset.name, asset.digests[algorithm])
+			} else {
+				fmt.Fprintf(&buf, "%s  %s\n", asset.digests[algorithm], asset.name)
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
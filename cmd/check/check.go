@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/concourse/github-release-resource"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	request := resource.NewCheckRequest()
 	inputRequest(&request)
 
@@ -17,7 +23,7 @@ func main() {
 	}
 
 	command := resource.NewCheckCommand(github)
-	response, err := command.Run(request)
+	response, err := command.Run(ctx, request)
 	if err != nil {
 		resource.Fatal("running command", err)
 	}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/concourse/github-release-resource"
+)
+
+// options are the flags shared by every subcommand. Source and params are
+// read from the YAML file first, then overridden by individual flags.
+type options struct {
+	configPath string
+	source     keyValues
+	params     keyValues
+
+	owner      string
+	repository string
+	apiURL     string
+	tokenEnv   string
+
+	json bool
+
+	withParams bool
+}
+
+func (o *options) register(flags *flag.FlagSet, withParams bool) {
+	o.source = keyValues{}
+	o.params = keyValues{}
+	o.withParams = withParams
+
+	flags.StringVar(&o.configPath, "config", "", "YAML `file` with source and params, laid out like a resource in a pipeline")
+	flags.Var(o.source, "s", "set a source `key=value`, e.g. -s tag_filter='v(.*)'; may be repeated")
+	if withParams {
+		flags.Var(o.params, "p", "set a param `key=value`, e.g. -p globs='[*.tgz]'; may be repeated")
+	}
+
+	flags.StringVar(&o.owner, "owner", "", "owner of the repository")
+	flags.StringVar(&o.repository, "repository", "", "name of the repository")
+	flags.StringVar(&o.apiURL, "api-url", "", "GitHub API URL, for GitHub Enterprise")
+	flags.StringVar(&o.tokenEnv, "token-env", "GITHUB_TOKEN", "environment `variable` to read the access token from when the source has none")
+
+	flags.BoolVar(&o.json, "json", false, "print the response as JSON instead of a table")
+}
+
+// decode fills the request with the source and params, keeping the defaults
+// of the request for anything that is not set.
+func (o *options) decode(target any) error {
+	config := struct {
+		Source map[string]any `yaml:"source"`
+		Params map[string]any `yaml:"params"`
+	}{}
+
+	if o.configPath != "" {
+		content, err := os.ReadFile(o.configPath)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.Unmarshal(content, &config)
+		if err != nil {
+			return fmt.Errorf("invalid config file '%s': %w", o.configPath, err)
+		}
+	}
+
+	if config.Source == nil {
+		config.Source = map[string]any{}
+	}
+	if config.Params == nil {
+		config.Params = map[string]any{}
+	}
+
+	for key, value := range map[string]string{
+		"owner":          o.owner,
+		"repository":     o.repository,
+		"github_api_url": o.apiURL,
+	} {
+		if value != "" {
+			config.Source[key] = value
+		}
+	}
+
+	for key, value := range o.source {
+		config.Source[key] = value
+	}
+	for key, value := range o.params {
+		config.Params[key] = value
+	}
+
+	request := map[string]any{"source": config.Source}
+	if o.withParams {
+		request["params"] = config.Params
+	}
+
+	// Round trip through JSON so that the json tags of the request decide
+	// the names of the keys, just like for requests from Concourse.
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(encoded)))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(target)
+}
+
+// applyToken reads the access token from the environment if the source is
+// not otherwise authenticated.
+func (o *options) applyToken(source *resource.Source) {
+	if source.AccessToken != "" || source.GitHubAppID != 0 || o.tokenEnv == "" {
+		return
+	}
+
+	source.AccessToken = os.Getenv(o.tokenEnv)
+}
+
+// keyValues collects repeated key=value flags. Values are parsed as YAML so
+// that booleans, numbers and lists can be given as well as strings.
+type keyValues map[string]any
+
+func (kv keyValues) String() string {
+	var pairs []string
+	for key, value := range kv {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (kv keyValues) Set(pair string) error {
+	key, raw, found := strings.Cut(pair, "=")
+	if !found || key == "" {
+		return fmt.Errorf("expected key=value, got '%s'", pair)
+	}
+
+	var value any
+	err := yaml.Unmarshal([]byte(raw), &value)
+	if err != nil {
+		return fmt.Errorf("invalid value for '%s': %w", key, err)
+	}
+
+	if value == nil {
+		value = raw
+	}
+
+	kv[key] = value
+	return nil
+}
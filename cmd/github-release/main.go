@@ -0,0 +1,319 @@
+// Command github-release runs the resource's check, get and put outside of
+// Concourse, and inspects releases, to reproduce pipeline behaviour locally.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/concourse/github-release-resource"
+)
+
+const usage = `usage: github-release <command> [flags] [directory]
+
+Runs the steps of the GitHub release resource outside of Concourse.
+
+Commands:
+  check  list the versions check would emit, optionally after -version
+  get    fetch a version into a directory, like a get step
+  put    create or update a release from a directory, like a put step
+  list   list the releases of the repository
+  show   show a release and its assets
+
+Source and params are read from a YAML file given with -config, laid out like
+a resource in a pipeline, and can be set or overridden with -s and -p:
+
+  github-release get -config resource.yml -s tag_filter='v(.*)' -p globs='[*.tgz]' ./out
+
+Run 'github-release <command> -h' for the flags of a command.
+`
+
+type command struct {
+	summary    string
+	withParams bool
+	positional string
+	extraFlags func(flags *flag.FlagSet)
+	run        func(ctx context.Context, opts *options, flags *flag.FlagSet) error
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var opts options
+	var version, tag, id string
+
+	commands := map[string]command{
+		"check": {
+			summary: "list the versions check would emit",
+			extraFlags: func(flags *flag.FlagSet) {
+				flags.StringVar(&version, "version", "", "`tag` of the current version; all versions are listed if unset")
+				flags.StringVar(&id, "version-id", "", "`id` of the current version")
+			},
+			run: func(ctx context.Context, opts *options, flags *flag.FlagSet) error {
+				return runCheck(ctx, opts, resource.Version{Tag: version, ID: id})
+			},
+		},
+		"get": {
+			summary:    "fetch a version into a directory",
+			withParams: true,
+			positional: "destination",
+			extraFlags: func(flags *flag.FlagSet) {
+				flags.StringVar(&tag, "tag", "", "`tag` of the version to fetch; the latest version is fetched if neither -tag nor -id is set")
+				flags.StringVar(&id, "id", "", "`id` of the release to fetch")
+			},
+			run: func(ctx context.Context, opts *options, flags *flag.FlagSet) error {
+				return runGet(ctx, opts, flags.Arg(0), resource.Version{Tag: tag, ID: id})
+			},
+		},
+		"put": {
+			summary:    "create or update a release from a directory",
+			withParams: true,
+			positional: "sources",
+			run: func(ctx context.Context, opts *options, flags *flag.FlagSet) error {
+				return runPut(ctx, opts, flags.Arg(0))
+			},
+		},
+		"list": {
+			summary: "list the releases of the repository",
+			run: func(ctx context.Context, opts *options, flags *flag.FlagSet) error {
+				return runList(ctx, opts)
+			},
+		},
+		"show": {
+			summary: "show a release and its assets",
+			extraFlags: func(flags *flag.FlagSet) {
+				flags.StringVar(&tag, "tag", "", "`tag` of the release to show")
+				flags.StringVar(&id, "id", "", "`id` of the release to show")
+			},
+			run: func(ctx context.Context, opts *options, flags *flag.FlagSet) error {
+				return runShow(ctx, opts, tag, id)
+			},
+		},
+	}
+
+	name := os.Args[1]
+	cmd, found := commands[name]
+	if !found {
+		if name != "-h" && name != "-help" && name != "help" {
+			fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n", name)
+		}
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		if cmd.positional != "" {
+			fmt.Fprintf(os.Stderr, "usage: github-release %s [flags] <%s directory>\n\n", name, cmd.positional)
+		} else {
+			fmt.Fprintf(os.Stderr, "usage: github-release %s [flags]\n\n", name)
+		}
+		fmt.Fprintf(os.Stderr, "%s.\n\nFlags:\n", cmd.summary)
+		flags.PrintDefaults()
+	}
+
+	opts.register(flags, cmd.withParams)
+	if cmd.extraFlags != nil {
+		cmd.extraFlags(flags)
+	}
+
+	flags.Parse(os.Args[2:])
+
+	if cmd.positional != "" && flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := cmd.run(ctx, &opts, flags)
+	if err != nil {
+		resource.Fatal("running "+name, err)
+	}
+}
+
+func runCheck(ctx context.Context, opts *options, version resource.Version) error {
+	request := resource.NewCheckRequest()
+	err := opts.decode(&request)
+	if err != nil {
+		return err
+	}
+
+	opts.applyToken(&request.Source)
+	request.Version = version
+
+	client, err := resource.NewGitHubClient(request.Source)
+	if err != nil {
+		return err
+	}
+
+	versions, err := resource.NewCheckCommand(client).Run(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return printJSON(versions)
+	}
+
+	printVersions(versions)
+	return nil
+}
+
+func runGet(ctx context.Context, opts *options, destDir string, version resource.Version) error {
+	request := resource.NewInRequest()
+	err := opts.decode(&request)
+	if err != nil {
+		return err
+	}
+
+	opts.applyToken(&request.Source)
+
+	client, err := resource.NewGitHubClient(request.Source)
+	if err != nil {
+		return err
+	}
+
+	if version.Tag == "" && version.ID == "" {
+		checkRequest := resource.NewCheckRequest()
+		checkRequest.Source = request.Source
+
+		versions, err := resource.NewCheckCommand(client).Run(ctx, checkRequest)
+		if err != nil {
+			return err
+		}
+
+		if len(versions) == 0 {
+			return errors.New("no versions found")
+		}
+
+		version = versions[len(versions)-1]
+	}
+
+	request.Version = &version
+
+	response, err := resource.NewInCommand(client, os.Stderr).Run(ctx, destDir, request)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return printJSON(response)
+	}
+
+	printResponse(response.Version, response.Metadata)
+	return nil
+}
+
+func runPut(ctx context.Context, opts *options, sourceDir string) error {
+	request := resource.NewOutRequest()
+	err := opts.decode(&request)
+	if err != nil {
+		return err
+	}
+
+	opts.applyToken(&request.Source)
+
+	client, err := resource.NewGitHubClient(request.Source)
+	if err != nil {
+		return err
+	}
+
+	response, err := resource.NewOutCommand(client, os.Stderr).Run(ctx, sourceDir, request)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return printJSON(response)
+	}
+
+	printResponse(response.Version, response.Metadata)
+	return nil
+}
+
+func runList(ctx context.Context, opts *options) error {
+	request := resource.NewCheckRequest()
+	err := opts.decode(&request)
+	if err != nil {
+		return err
+	}
+
+	opts.applyToken(&request.Source)
+
+	client, err := resource.NewGitHubClient(request.Source)
+	if err != nil {
+		return err
+	}
+
+	releases, err := client.ListReleases(ctx)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return printJSON(releases)
+	}
+
+	printReleases(releases)
+	return nil
+}
+
+func runShow(ctx context.Context, opts *options, tag, id string) error {
+	if (tag == "") == (id == "") {
+		return errors.New("exactly one of -tag or -id must be set")
+	}
+
+	request := resource.NewCheckRequest()
+	err := opts.decode(&request)
+	if err != nil {
+		return err
+	}
+
+	opts.applyToken(&request.Source)
+
+	client, err := resource.NewGitHubClient(request.Source)
+	if err != nil {
+		return err
+	}
+
+	var release *github.RepositoryRelease
+	if tag != "" {
+		release, err = client.GetReleaseByTag(ctx, tag)
+	} else {
+		var n int
+		n, err = strconv.Atoi(id)
+		if err != nil {
+			return fmt.Errorf("invalid release id '%s'", id)
+		}
+		release, err = client.GetRelease(ctx, n)
+	}
+	if err != nil {
+		return err
+	}
+
+	assets, err := client.ListReleaseAssets(ctx, *release)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		release.Assets = assets
+		return printJSON(release)
+	}
+
+	printRelease(release, assets)
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/concourse/github-release-resource"
+)
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+}
+
+func printVersions(versions []resource.Version) {
+	if len(versions) == 0 {
+		fmt.Println("no versions")
+		return
+	}
+
+	table := newTable()
+	fmt.Fprintln(table, "TAG\tID\tTIMESTAMP")
+	for _, version := range versions {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", version.Tag, version.ID, formatTime(version.Timestamp))
+	}
+	table.Flush()
+}
+
+func printResponse(version resource.Version, metadata []resource.MetadataPair) {
+	table := newTable()
+	fmt.Fprintln(table, "version:")
+	fmt.Fprintf(table, "  tag:\t%s\n", version.Tag)
+	fmt.Fprintf(table, "  id:\t%s\n", version.ID)
+	fmt.Fprintf(table, "  timestamp:\t%s\n", formatTime(version.Timestamp))
+	table.Flush()
+
+	if len(metadata) == 0 {
+		return
+	}
+
+	fmt.Println("metadata:")
+	table = newTable()
+	for _, pair := range metadata {
+		printField(table, "  ", pair.Name, pair.Value)
+	}
+	table.Flush()
+}
+
+func printReleases(releases []*github.RepositoryRelease) {
+	if len(releases) == 0 {
+		fmt.Println("no releases")
+		return
+	}
+
+	table := newTable()
+	fmt.Fprintln(table, "TAG\tNAME\tID\tSTATE\tCREATED")
+	for _, release := range releases {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\t%s\n",
+			release.GetTagName(),
+			release.GetName(),
+			release.GetID(),
+			releaseState(release),
+			formatTime(release.GetCreatedAt().Time),
+		)
+	}
+	table.Flush()
+}
+
+func printRelease(release *github.RepositoryRelease, assets []*github.ReleaseAsset) {
+	table := newTable()
+	printField(table, "", "name", release.GetName())
+	printField(table, "", "tag", release.GetTagName())
+	printField(table, "", "id", fmt.Sprint(release.GetID()))
+	printField(table, "", "state", releaseState(release))
+	printField(table, "", "commitish", release.GetTargetCommitish())
+	printField(table, "", "created", formatTime(release.GetCreatedAt().Time))
+	printField(table, "", "published", formatTime(release.GetPublishedAt().Time))
+	printField(table, "", "url", release.GetHTMLURL())
+	printField(table, "", "body", release.GetBody())
+	table.Flush()
+
+	if len(assets) == 0 {
+		fmt.Println("assets: none")
+		return
+	}
+
+	fmt.Println("assets:")
+	table = newTable()
+	fmt.Fprintln(table, "  NAME\tSIZE\tSTATE\tDIGEST")
+	for _, asset := range assets {
+		fmt.Fprintf(table, "  %s\t%d\t%s\t%s\n", asset.GetName(), asset.GetSize(), asset.GetState(), asset.GetDigest())
+	}
+	table.Flush()
+}
+
+// printField prints a name and value, indenting the lines of values that
+// span several below the name so that they do not break the table.
+func printField(w io.Writer, indent, name, value string) {
+	value = strings.TrimRight(value, "\n")
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(w, "%s%s:\t%s\n", indent, name, value)
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s:\t|\n", indent, name)
+	for _, line := range strings.Split(value, "\n") {
+		fmt.Fprintf(w, "%s    %s\n", indent, line)
+	}
+}
+
+func releaseState(release *github.RepositoryRelease) string {
+	switch {
+	case release.GetDraft():
+		return "draft"
+	case release.GetPrerelease():
+		return "pre-release"
+	default:
+		return "release"
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
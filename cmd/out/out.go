@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/concourse/github-release-resource"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	if len(os.Args) < 2 {
 		resource.Sayf("usage: %s <sources directory>\n", os.Args[0])
 		os.Exit(1)
@@ -24,7 +30,7 @@ func main() {
 	}
 
 	command := resource.NewOutCommand(github, os.Stderr)
-	response, err := command.Run(sourceDir, request)
+	response, err := command.Run(ctx, sourceDir, request)
 	if err != nil {
 		resource.Fatal("running command", err)
 	}
@@ -0,0 +1,420 @@
+package resource_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/google/go-github/v74/github"
+
+	resource "github.com/concourse/github-release-resource"
+	"github.com/concourse/github-release-resource/emulator"
+)
+
+var _ = Describe("End to end against the emulator", func() {
+	var (
+		server *emulator.Server
+		repo   *emulator.Repository
+		source resource.Source
+	)
+
+	BeforeEach(func() {
+		server = emulator.NewServer()
+		repo = server.Repository("concourse", "concourse")
+
+		source = resource.Source{
+			Owner:        "concourse",
+			Repository:   "concourse",
+			GitHubAPIURL: server.URL(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func() *resource.GitHubClient {
+		client, err := resource.NewGitHubClient(source)
+		Ω(err).ShouldNot(HaveOccurred())
+		return client
+	}
+
+	Describe("the GitHub client", func() {
+		var release *github.RepositoryRelease
+
+		BeforeEach(func() {
+			release = repo.CreateRelease(github.RepositoryRelease{
+				TagName:   github.String("v1.0.0"),
+				Name:      github.String("First"),
+				CreatedAt: &github.Timestamp{Time: exampleTimeStamp(1)},
+			})
+			repo.CreateRelease(github.RepositoryRelease{
+				TagName:   github.String("v1.1.0"),
+				Draft:     github.Bool(true),
+				CreatedAt: &github.Timestamp{Time: exampleTimeStamp(2)},
+			})
+		})
+
+		It("lists releases through the REST API without a token", func() {
+			releases, err := newClient().ListReleases(context.Background())
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(releases).Should(HaveLen(1))
+			Ω(*releases[0].TagName).Should(Equal("v1.0.0"))
+		})
+
+		It("lists releases, including drafts, through the GraphQL API with a token", func() {
+			server.RequireToken("abc123")
+			source.AccessToken = "abc123"
+
+			releases, err := newClient().ListReleases(context.Background())
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(releases).Should(HaveLen(2))
+			Ω(*releases[0].TagName).Should(Equal("v1.1.0"))
+			Ω(*releases[1].ID).Should(Equal(*release.ID))
+			Ω(releases[1].CreatedAt.Time).Should(Equal(exampleTimeStamp(1)))
+		})
+
+		It("finds the latest release, skipping drafts", func() {
+			latest, err := newClient().GetLatestRelease(context.Background())
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(latest.GetID()).Should(Equal(release.GetID()))
+		})
+
+		It("rejects bad credentials", func() {
+			server.RequireToken("abc123")
+			source.AccessToken = "wrong"
+
+			_, err := newClient().GetReleaseByTag(context.Background(), "v1.0.0")
+			Ω(err).Should(MatchError(ContainSubstring("401 Bad credentials")))
+		})
+
+		It("pages through many assets", func() {
+			for i := range 120 {
+				repo.UploadAsset(*release.ID, "asset-"+string(rune('a'+i/26))+string(rune('a'+i%26)), []byte("x"))
+			}
+
+			assets, err := newClient().ListReleaseAssets(context.Background(), *release)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(assets).Should(HaveLen(120))
+		})
+
+		It("uploads, renames, downloads and deletes assets", func() {
+			client := newClient()
+
+			path := filepath.Join(GinkgoT().TempDir(), "example.tgz")
+			Ω(os.WriteFile(path, []byte("example"), 0644)).Should(Succeed())
+
+			f, err := os.Open(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			defer f.Close()
+
+			Ω(client.UploadReleaseAsset(context.Background(), *release, "example.tgz.tmp", f)).Should(Succeed())
+
+			assets, err := client.ListReleaseAssets(context.Background(), *release)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(assets).Should(HaveLen(1))
+			Ω(*assets[0].Size).Should(Equal(7))
+			sum := sha256.Sum256([]byte("example"))
+			Ω(*assets[0].Digest).Should(Equal("sha256:" + hex.EncodeToString(sum[:])))
+
+			assets[0].Name = github.String("example.tgz")
+			renamed, err := client.UpdateReleaseAsset(context.Background(), *assets[0])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*renamed.Name).Should(Equal("example.tgz"))
+
+			content, err := client.DownloadReleaseAsset(context.Background(), *renamed, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			body, err := io.ReadAll(content)
+			content.Close()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(body)).Should(Equal("example"))
+
+			content, err = client.DownloadReleaseAsset(context.Background(), *renamed, 3)
+			Ω(err).ShouldNot(HaveOccurred())
+			body, err = io.ReadAll(content)
+			content.Close()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(body)).Should(Equal("mple"))
+
+			Ω(client.DeleteReleaseAsset(context.Background(), *renamed)).Should(Succeed())
+			Ω(repo.Assets(*release.ID)).Should(BeEmpty())
+		})
+
+		It("resolves annotated tags to their commit", func() {
+			repo.CreateAnnotatedTag("v2.0.0", "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222")
+
+			sha, err := newClient().ResolveTagToCommitSHA(context.Background(), "v2.0.0")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(sha).Should(Equal("2222222222222222222222222222222222222222"))
+		})
+
+		It("links to source archives of the tag", func() {
+			u, err := newClient().GetTarballLink(context.Background(), "v1.0.0")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(u.String()).Should(HavePrefix(server.URL()))
+		})
+	})
+
+	Describe("the commands", func() {
+		var release *github.RepositoryRelease
+
+		BeforeEach(func() {
+			source.AccessToken = "abc123"
+
+			release = repo.CreateRelease(github.RepositoryRelease{
+				TagName: github.String("v1.0.0"),
+				Body:    github.String("*markdown*"),
+			})
+			repo.UploadAsset(*release.ID, "example.txt", []byte("example"))
+		})
+
+		It("puts a release that check and get then see", func() {
+			sourcesDir := GinkgoT().TempDir()
+			file(filepath.Join(sourcesDir, "name"), "v1.1.0")
+			file(filepath.Join(sourcesDir, "tag"), "v1.1.0")
+			file(filepath.Join(sourcesDir, "new.txt"), "new")
+
+			outRequest := resource.NewOutRequest()
+			outRequest.Source = source
+			outRequest.Params = resource.OutParams{
+				NamePath: "name",
+				TagPath:  "tag",
+				Globs:    []string{"*.txt"},
+			}
+
+			outResponse, err := resource.NewOutCommand(newClient(), io.Discard).Run(context.Background(), sourcesDir, outRequest)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(outResponse.Version.Tag).Should(Equal("v1.1.0"))
+
+			checkRequest := resource.NewCheckRequest()
+			checkRequest.Source = source
+			checkRequest.Version = resource.Version{Tag: "v1.0.0"}
+
+			versions, err := resource.NewCheckCommand(newClient()).Run(context.Background(), checkRequest)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(versions).Should(HaveLen(2))
+			Ω(versions[1].Tag).Should(Equal("v1.1.0"))
+
+			destDir := GinkgoT().TempDir()
+
+			inRequest := resource.NewInRequest()
+			inRequest.Source = source
+			inRequest.Version = &versions[1]
+			inRequest.Params.IncludeSourceTarball = true
+			inRequest.Params.IncludeLatest = true
+
+			_, err = resource.NewInCommand(newClient(), io.Discard).Run(context.Background(), destDir, inRequest)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(filepath.Join(destDir, "new.txt")).Should(BeAnExistingFile())
+			Ω(filepath.Join(destDir, "source.tar.gz")).Should(BeAnExistingFile())
+			Ω(filepath.Join(destDir, "commit_sha")).Should(BeAnExistingFile())
+			Ω(os.ReadFile(filepath.Join(destDir, "release.json"))).Should(And(
+				ContainSubstring(`"tag": "v1.1.0"`),
+				ContainSubstring(`"latest": true`),
+			))
+		})
+
+		It("filters releases by body and author through GraphQL", func() {
+			repo.CreateRelease(github.RepositoryRelease{
+				TagName: github.String("v1.1.0"),
+				Body:    github.String("DO NOT USE"),
+			})
+			repo.CreateRelease(github.RepositoryRelease{
+				TagName: github.String("v1.2.0"),
+				Author:  &github.User{Login: github.String("mallory")},
+			})
+
+			checkRequest := resource.NewCheckRequest()
+			checkRequest.Source = source
+			checkRequest.Source.BodyExcludes = []string{"DO NOT USE"}
+			checkRequest.Source.Authors = []string{release.GetAuthor().GetLogin(), "octocat"}
+			checkRequest.Version = resource.Version{Tag: "v1.0.0"}
+
+			versions, err := resource.NewCheckCommand(newClient()).Run(context.Background(), checkRequest)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(versions).Should(HaveLen(1))
+			Ω(versions[0].Tag).Should(Equal("v1.0.0"))
+		})
+
+		DescribeTable("checks for required assets",
+			func(accessToken string) {
+				source.AccessToken = accessToken
+
+				next := repo.CreateRelease(github.RepositoryRelease{
+					TagName: github.String("v1.1.0"),
+				})
+
+				checkRequest := resource.NewCheckRequest()
+				checkRequest.Source = source
+				checkRequest.Source.RequiredAssets = []string{"*.txt"}
+				checkRequest.Version = resource.Version{Tag: "v1.0.0"}
+
+				versions, err := resource.NewCheckCommand(newClient()).Run(context.Background(), checkRequest)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(versions).Should(HaveLen(1))
+				Ω(versions[0].Tag).Should(Equal("v1.0.0"))
+
+				repo.UploadAsset(*next.ID, "next.txt", []byte("next"))
+
+				versions, err = resource.NewCheckCommand(newClient()).Run(context.Background(), checkRequest)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(versions).Should(HaveLen(2))
+				Ω(versions[1].Tag).Should(Equal("v1.1.0"))
+			},
+			Entry("of releases listed through GraphQL", "abc123"),
+			Entry("of releases listed through REST", ""),
+		)
+	})
+
+	Describe("the binaries", Ordered, func() {
+		var check, in, out, cli string
+
+		BeforeAll(func() {
+			var err error
+
+			check, err = gexec.Build("github.com/concourse/github-release-resource/cmd/check")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			in, err = gexec.Build("github.com/concourse/github-release-resource/cmd/in")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			out, err = gexec.Build("github.com/concourse/github-release-resource/cmd/out")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			cli, err = gexec.Build("github.com/concourse/github-release-resource/cmd/github-release")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			DeferCleanup(gexec.CleanupBuildArtifacts)
+		})
+
+		run := func(stdout any, binary string, request any, args ...string) {
+			input, err := json.Marshal(request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			cmd := exec.Command(binary, args...)
+			cmd.Stdin = bytes.NewReader(input)
+
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Ω(err).ShouldNot(HaveOccurred())
+			Eventually(session, 30*time.Second).Should(gexec.Exit(0))
+
+			Ω(json.Unmarshal(session.Out.Contents(), stdout)).Should(Succeed())
+		}
+
+		It("puts, checks and gets a release", func() {
+			sourcesDir := GinkgoT().TempDir()
+			file(filepath.Join(sourcesDir, "name"), "v2.0.0")
+			file(filepath.Join(sourcesDir, "tag"), "v2.0.0")
+			file(filepath.Join(sourcesDir, "example.txt"), "example")
+
+			var outResponse resource.OutResponse
+			run(&outResponse, out, resource.OutRequest{
+				Source: source,
+				Params: resource.OutParams{
+					NamePath: "name",
+					TagPath:  "tag",
+					Globs:    []string{"example.txt"},
+				},
+			}, sourcesDir)
+			Ω(outResponse.Version.Tag).Should(Equal("v2.0.0"))
+
+			var versions []resource.Version
+			run(&versions, check, resource.CheckRequest{Source: source})
+			Ω(versions).Should(HaveLen(1))
+			Ω(versions[0].Tag).Should(Equal("v2.0.0"))
+
+			destDir := GinkgoT().TempDir()
+
+			var inResponse resource.InResponse
+			run(&inResponse, in, resource.InRequest{
+				Source:  source,
+				Version: &versions[0],
+			}, destDir)
+			Ω(inResponse.Version).Should(Equal(versions[0]))
+
+			content, err := os.ReadFile(filepath.Join(destDir, "example.txt"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("example"))
+		})
+
+		Describe("the developer CLI", func() {
+			runCLI := func(args ...string) *gexec.Session {
+				cmd := exec.Command(cli, args...)
+				cmd.Env = append(os.Environ(), "CLI_TOKEN=abc123")
+
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Ω(err).ShouldNot(HaveOccurred())
+				Eventually(session, 30*time.Second).Should(gexec.Exit(0))
+
+				return session
+			}
+
+			var configPath string
+
+			BeforeEach(func() {
+				server.RequireToken("abc123")
+
+				configPath = filepath.Join(GinkgoT().TempDir(), "resource.yml")
+				file(configPath, "source:\n"+
+					"  owner: concourse\n"+
+					"  repository: concourse\n"+
+					"  github_api_url: "+server.URL()+"\n")
+			})
+
+			It("puts, lists, shows, checks and gets releases", func() {
+				sourcesDir := GinkgoT().TempDir()
+				file(filepath.Join(sourcesDir, "tag"), "v3.0.0")
+				file(filepath.Join(sourcesDir, "example.txt"), "example")
+
+				session := runCLI("put", "-config", configPath, "-token-env", "CLI_TOKEN",
+					"-p", "name=tag", "-p", "tag=tag", "-p", "globs=[example.txt]", sourcesDir)
+				Ω(session.Out).Should(gbytes.Say(`tag:\s+v3.0.0`))
+
+				session = runCLI("list", "-config", configPath, "-token-env", "CLI_TOKEN")
+				Ω(session.Out).Should(gbytes.Say(`TAG\s+NAME\s+ID\s+STATE\s+CREATED`))
+				Ω(session.Out).Should(gbytes.Say(`v3.0.0\s+v3.0.0\s+\d+\s+release`))
+
+				session = runCLI("show", "-config", configPath, "-token-env", "CLI_TOKEN", "-tag", "v3.0.0", "-json")
+				var release github.RepositoryRelease
+				Ω(json.Unmarshal(session.Out.Contents(), &release)).Should(Succeed())
+				Ω(release.Assets).Should(HaveLen(1))
+				Ω(*release.Assets[0].Name).Should(Equal("example.txt"))
+
+				session = runCLI("check", "-owner", "concourse", "-repository", "concourse",
+					"-api-url", server.URL(), "-token-env", "CLI_TOKEN", "-s", "tag_filter=v(.*)")
+				Ω(session.Out).Should(gbytes.Say(`3.0.0\s+\d+`))
+
+				destDir := GinkgoT().TempDir()
+				runCLI("get", "-config", configPath, "-token-env", "CLI_TOKEN", destDir)
+
+				content, err := os.ReadFile(filepath.Join(destDir, "example.txt"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(Equal("example"))
+			})
+
+			It("fails on unknown source keys", func() {
+				cmd := exec.Command(cli, "list", "-config", configPath, "-s", "ownr=concourse")
+
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Ω(err).ShouldNot(HaveOccurred())
+				Eventually(session, 30*time.Second).Should(gexec.Exit(1))
+				Ω(session.Err).Should(gbytes.Say(`unknown field "ownr"`))
+			})
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package emulator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"maps"
+	"slices"
+)
+
+// tarball builds a gzipped tarball of the files under the prefix directory,
+// laid out like the source archives GitHub generates.
+func tarball(prefix string, files map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     prefix,
+		Mode:     0755,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     prefix + name,
+			Mode:     0644,
+			Size:     int64(len(files[name])),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tw.Write([]byte(files[name]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// zipball builds a zip archive of the files under the prefix directory.
+func zipball(prefix string, files map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	_, err := zw.Create(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		w, err := zw.Create(prefix + name)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = w.Write([]byte(files[name]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
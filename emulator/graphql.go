@@ -0,0 +1,595 @@
+package emulator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/scanner"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// The GraphQL client decodes responses strictly, so the emulator has to
+// answer with exactly the fields that were selected. Queries are parsed into
+// selections and executed against a schema of objects built from maps, in
+// which fields that take arguments are resolver functions.
+
+type object map[string]any
+
+type resolver func(args map[string]any) (any, error)
+
+type selection struct {
+	name      string
+	alias     string
+	args      map[string]any
+	fragment  string
+	selection []selection
+}
+
+func (s *Server) graphql(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	selections, err := parseQuery(body.Query, body.Variables)
+	if err != nil {
+		writeGraphQLError(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := execute(s.queryObject(), selections)
+	if err != nil {
+		writeGraphQLError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func writeGraphQLError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":   nil,
+		"errors": []map[string]string{{"message": err.Error()}},
+	})
+}
+
+func (s *Server) queryObject() object {
+	return object{
+		"__typename": "Query",
+		"repository": resolver(func(args map[string]any) (any, error) {
+			owner, _ := args["owner"].(string)
+			name, _ := args["name"].(string)
+
+			repo, found := s.lookup(owner, name)
+			if !found {
+				return nil, fmt.Errorf("Could not resolve to a Repository with the name '%s/%s'.", owner, name)
+			}
+
+			return repo.graphqlObject(), nil
+		}),
+	}
+}
+
+func (r *Repository) graphqlObject() object {
+	return object{
+		"__typename":    "Repository",
+		"name":          r.name,
+		"nameWithOwner": r.owner + "/" + r.name,
+		"releases": resolver(func(args map[string]any) (any, error) {
+			releases := r.sortedReleases()
+
+			if orderBy, ok := args["orderBy"].(map[string]any); ok {
+				if orderBy["field"] == "NAME" {
+					return nil, fmt.Errorf("ordering releases by NAME is not emulated")
+				}
+				if orderBy["direction"] == "ASC" {
+					for i, j := 0, len(releases)-1; i < j; i, j = i+1, j-1 {
+						releases[i], releases[j] = releases[j], releases[i]
+					}
+				}
+			}
+
+			var nodes []any
+			for _, rel := range releases {
+				nodes = append(nodes, r.releaseObject(rel))
+			}
+
+			return connection(nodes, args)
+		}),
+		"ref": resolver(func(args map[string]any) (any, error) {
+			name, _ := args["qualifiedName"].(string)
+			name = strings.TrimPrefix(name, "refs/")
+
+			object, found := r.refs[name]
+			if !found {
+				return nil, nil
+			}
+
+			return r.refObject(name, object), nil
+		}),
+	}
+}
+
+func (r *Repository) releaseObject(rel *release) object {
+	data := rel.data
+	rendered := r.render(rel)
+
+	tagName := ""
+	if data.TagName != nil {
+		tagName = *data.TagName
+	}
+
+	tag := any(nil)
+	if ref, found := r.refs["tags/"+tagName]; found {
+		tag = r.refObject("tags/"+tagName, ref)
+	}
+
+	tagCommit := any(nil)
+	if commit, found := r.resolveRef(tagName); found && tagName != "" {
+		tagCommit = commitObject(commit)
+	}
+
+	author := any(nil)
+	if data.Author != nil && data.Author.Login != nil {
+		author = object{"__typename": "User", "login": *data.Author.Login}
+	}
+
+	return object{
+		"__typename":   "Release",
+		"id":           base64.StdEncoding.EncodeToString([]byte("07:Release" + strconv.FormatInt(*data.ID, 10))),
+		"databaseId":   *data.ID,
+		"name":         *data.Name,
+		"tagName":      tagName,
+		"description":  *data.Body,
+		"isDraft":      *data.Draft,
+		"isPrerelease": *data.Prerelease,
+		"url":          *rendered.HTMLURL,
+		"createdAt":    dateTime(data.CreatedAt),
+		"publishedAt":  dateTime(data.PublishedAt),
+		"author":       author,
+		"tag":          tag,
+		"tagCommit":    tagCommit,
+		"releaseAssets": resolver(func(args map[string]any) (any, error) {
+			var nodes []any
+			for _, a := range rel.assets {
+				if name, ok := args["name"].(string); ok && name != *a.data.Name {
+					continue
+				}
+				nodes = append(nodes, r.assetObject(rel, a))
+			}
+
+			return connection(nodes, args)
+		}),
+	}
+}
+
+func (r *Repository) assetObject(rel *release, a *asset) object {
+	rendered := r.renderAsset(rel, a)
+
+	return object{
+		"__typename":    "ReleaseAsset",
+		"id":            base64.StdEncoding.EncodeToString([]byte("12:ReleaseAsset" + strconv.FormatInt(*a.data.ID, 10))),
+		"databaseId":    *a.data.ID,
+		"name":          *a.data.Name,
+		"contentType":   *a.data.ContentType,
+		"size":          *a.data.Size,
+		"downloadCount": *a.data.DownloadCount,
+		"downloadUrl":   *rendered.BrowserDownloadURL,
+		"url":           *rendered.URL,
+		"createdAt":     dateTime(a.data.CreatedAt),
+		"updatedAt":     dateTime(a.data.UpdatedAt),
+	}
+}
+
+func (r *Repository) refObject(name string, target gitObject) object {
+	short := name[strings.Index(name, "/")+1:]
+
+	return object{
+		"__typename": "Ref",
+		"name":       short,
+		"prefix":     "refs/" + strings.TrimSuffix(name, short),
+		"target":     r.gitObject(target),
+	}
+}
+
+func (r *Repository) gitObject(target gitObject) object {
+	if target.typ != "tag" {
+		return commitObject(target.sha)
+	}
+
+	tag := r.tags[target.sha]
+
+	return object{
+		"__typename": "Tag",
+		"oid":        target.sha,
+		"name":       tag.name,
+		"target":     r.gitObject(tag.target),
+	}
+}
+
+func commitObject(sha string) object {
+	return object{
+		"__typename":      "Commit",
+		"oid":             sha,
+		"abbreviatedOid":  sha[:7],
+		"commitUrl":       "",
+		"messageHeadline": "",
+	}
+}
+
+func dateTime(t *github.Timestamp) any {
+	if t == nil {
+		return nil
+	}
+
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+// connection pages through the nodes with the first and after arguments,
+// using their index as cursor.
+func connection(nodes []any, args map[string]any) (any, error) {
+	start := 0
+	if after, ok := args["after"].(string); ok {
+		decoded, err := base64.StdEncoding.DecodeString(after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %q", after)
+		}
+
+		index, err := strconv.Atoi(strings.TrimPrefix(string(decoded), "cursor:"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %q", after)
+		}
+
+		start = min(index+1, len(nodes))
+	}
+
+	end := len(nodes)
+	if first, ok := args["first"].(float64); ok {
+		if first < 0 || first > 100 {
+			return nil, fmt.Errorf("requesting %v records on the connection exceeds the limit of 100 records", first)
+		}
+		end = min(start+int(first), len(nodes))
+	}
+
+	cursor := func(i int) string {
+		return base64.StdEncoding.EncodeToString([]byte("cursor:" + strconv.Itoa(i)))
+	}
+
+	edges := []any{}
+	page := []any{}
+	for i := start; i < end; i++ {
+		edges = append(edges, object{"cursor": cursor(i), "node": nodes[i]})
+		page = append(page, nodes[i])
+	}
+
+	pageInfo := object{
+		"hasNextPage":     end < len(nodes),
+		"hasPreviousPage": start > 0,
+		"startCursor":     nil,
+		"endCursor":       nil,
+	}
+	if end > start {
+		pageInfo["startCursor"] = cursor(start)
+		pageInfo["endCursor"] = cursor(end - 1)
+	}
+
+	return object{
+		"edges":      edges,
+		"nodes":      page,
+		"pageInfo":   pageInfo,
+		"totalCount": len(nodes),
+	}, nil
+}
+
+// execute resolves the selections against the value.
+func execute(value any, selections []selection) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+
+	case object:
+		result := map[string]any{}
+		err := executeObject(v, selections, result)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+
+	case []any:
+		results := []any{}
+		for _, item := range v {
+			result, err := execute(item, selections)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		return results, nil
+
+	default:
+		if len(selections) > 0 {
+			return nil, fmt.Errorf("selections can't be made on scalars")
+		}
+		return v, nil
+	}
+}
+
+func executeObject(obj object, selections []selection, result map[string]any) error {
+	for _, sel := range selections {
+		if sel.fragment != "" {
+			if obj["__typename"] == sel.fragment {
+				err := executeObject(obj, sel.selection, result)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		value, found := obj[sel.name]
+		if !found {
+			return fmt.Errorf("Field '%s' doesn't exist on type '%v'", sel.name, obj["__typename"])
+		}
+
+		if resolve, ok := value.(resolver); ok {
+			var err error
+			value, err = resolve(sel.args)
+			if err != nil {
+				return err
+			}
+		}
+
+		if (len(sel.selection) > 0) != isComposite(value) && value != nil {
+			return fmt.Errorf("Field '%s' has an invalid selection", sel.name)
+		}
+
+		resolved, err := execute(value, sel.selection)
+		if err != nil {
+			return err
+		}
+
+		key := sel.name
+		if sel.alias != "" {
+			key = sel.alias
+		}
+		result[key] = resolved
+	}
+
+	return nil
+}
+
+func isComposite(value any) bool {
+	switch v := value.(type) {
+	case object:
+		return true
+	case []any:
+		return len(v) == 0 || isComposite(v[0])
+	default:
+		return false
+	}
+}
+
+// parser reads the subset of the GraphQL query language the GraphQL client
+// generates: a single operation with variables, fields with arguments and
+// aliases, and inline fragments.
+type parser struct {
+	scanner   scanner.Scanner
+	token     rune
+	variables map[string]any
+	err       error
+}
+
+func parseQuery(query string, variables map[string]any) ([]selection, error) {
+	p := &parser{variables: variables}
+	p.scanner.Init(strings.NewReader(query))
+	p.scanner.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats | scanner.ScanStrings
+	p.scanner.Error = func(_ *scanner.Scanner, msg string) {
+		p.fail("%s", msg)
+	}
+	p.next()
+
+	if p.token == scanner.Ident && (p.text() == "query" || p.text() == "mutation") {
+		if p.text() == "mutation" {
+			return nil, fmt.Errorf("mutations are not emulated")
+		}
+		p.next()
+
+		if p.token == scanner.Ident {
+			p.next()
+		}
+
+		if p.token == '(' {
+			p.skipBalanced('(', ')')
+		}
+	}
+
+	selections := p.selectionSet()
+	if p.err == nil && p.token != scanner.EOF {
+		p.fail("unexpected %q after the operation", p.text())
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return selections, nil
+}
+
+func (p *parser) next() {
+	p.token = p.scanner.Scan()
+	for p.token == ',' {
+		p.token = p.scanner.Scan()
+	}
+}
+
+func (p *parser) text() string {
+	return p.scanner.TokenText()
+}
+
+func (p *parser) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf("could not parse query: "+format, args...)
+	}
+	p.token = scanner.EOF
+}
+
+func (p *parser) expect(token rune) {
+	if p.token != token {
+		p.fail("expected %q, got %q", string(token), p.text())
+		return
+	}
+	p.next()
+}
+
+func (p *parser) skipBalanced(open, close rune) {
+	depth := 0
+	for p.token != scanner.EOF {
+		switch p.token {
+		case open:
+			depth++
+		case close:
+			depth--
+		}
+		p.next()
+		if depth == 0 {
+			return
+		}
+	}
+	p.fail("unbalanced %q", string(open))
+}
+
+func (p *parser) selectionSet() []selection {
+	p.expect('{')
+
+	var selections []selection
+	for p.err == nil && p.token != '}' {
+		selections = append(selections, p.selection())
+	}
+
+	p.expect('}')
+
+	return selections
+}
+
+func (p *parser) selection() selection {
+	if p.token == '.' {
+		for range 3 {
+			p.expect('.')
+		}
+
+		if p.token != scanner.Ident || p.text() != "on" {
+			p.fail("only inline fragments are supported")
+			return selection{}
+		}
+		p.next()
+
+		fragment := p.text()
+		p.expect(scanner.Ident)
+
+		return selection{fragment: fragment, selection: p.selectionSet()}
+	}
+
+	sel := selection{name: p.text()}
+	p.expect(scanner.Ident)
+
+	if p.token == ':' {
+		p.next()
+		sel.alias, sel.name = sel.name, p.text()
+		p.expect(scanner.Ident)
+	}
+
+	sel.args = map[string]any{}
+	if p.token == '(' {
+		p.next()
+		for p.err == nil && p.token != ')' {
+			name := p.text()
+			p.expect(scanner.Ident)
+			p.expect(':')
+			sel.args[name] = p.value()
+		}
+		p.expect(')')
+	}
+
+	if p.token == '{' {
+		sel.selection = p.selectionSet()
+	}
+
+	return sel
+}
+
+func (p *parser) value() any {
+	switch p.token {
+	case '$':
+		p.next()
+		name := p.text()
+		p.expect(scanner.Ident)
+		return p.variables[name]
+
+	case scanner.Int, scanner.Float:
+		n, err := strconv.ParseFloat(p.text(), 64)
+		if err != nil {
+			p.fail("invalid number %q", p.text())
+		}
+		p.next()
+		return n
+
+	case scanner.String:
+		s, err := strconv.Unquote(p.text())
+		if err != nil {
+			p.fail("invalid string %q", p.text())
+		}
+		p.next()
+		return s
+
+	case scanner.Ident:
+		text := p.text()
+		p.next()
+		switch text {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		default:
+			return text
+		}
+
+	case '{':
+		p.next()
+		fields := map[string]any{}
+		for p.err == nil && p.token != '}' {
+			name := p.text()
+			p.expect(scanner.Ident)
+			p.expect(':')
+			fields[name] = p.value()
+		}
+		p.expect('}')
+		return fields
+
+	case '[':
+		p.next()
+		var values []any
+		for p.err == nil && p.token != ']' {
+			values = append(values, p.value())
+		}
+		p.expect(']')
+		return values
+
+	default:
+		p.fail("unexpected %q", p.text())
+		return nil
+	}
+}
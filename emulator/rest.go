@@ -0,0 +1,704 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+type authenticatedKey struct{}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /repos/{owner}/{repo}/releases", s.listReleases)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/releases", s.createRelease)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/releases/{path...}", s.getRelease)
+	mux.HandleFunc("PATCH /repos/{owner}/{repo}/releases/{path...}", s.editRelease)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/releases/{id}/assets", s.uploadAsset)
+	mux.HandleFunc("DELETE /repos/{owner}/{repo}/releases/assets/{id}", s.deleteAsset)
+
+	mux.HandleFunc("GET /repos/{owner}/{repo}/git/ref/{ref...}", s.getRef)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/git/tags/{sha}", s.getTag)
+
+	mux.HandleFunc("GET /repos/{owner}/{repo}/tarball/{ref...}", s.archiveLink("tar.gz"))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/zipball/{ref...}", s.archiveLink("zip"))
+
+	mux.HandleFunc("POST /graphql", s.graphql)
+
+	// Public URLs, which like their counterparts on GitHub redirect to
+	// storage that does not need credentials.
+	mux.HandleFunc("GET /{owner}/{repo}/releases/download/{tag}/{name}", s.browserDownload)
+	mux.HandleFunc("GET /_storage/assets/{id}", s.storedAsset)
+	mux.HandleFunc("GET /_storage/archive", s.storedArchive)
+
+	return s.authenticate(mux)
+}
+
+// authenticate checks the credentials of API requests. Requests without any
+// are treated as anonymous, which hides draft releases and is refused by the
+// GraphQL API.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		isAPI := strings.HasPrefix(req.URL.Path, "/repos/") || req.URL.Path == "/graphql"
+		if !isAPI {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		header := req.Header.Get("Authorization")
+		token := strings.TrimPrefix(strings.TrimPrefix(header, "Bearer "), "token ")
+
+		s.mu.Lock()
+		required := s.token
+		s.mu.Unlock()
+
+		if required != "" && token != required {
+			writeError(w, http.StatusUnauthorized, "Bad credentials")
+			return
+		}
+
+		if header == "" && req.URL.Path == "/graphql" {
+			writeError(w, http.StatusUnauthorized, "This endpoint requires you to be authenticated.")
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), authenticatedKey{}, header != "")
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func authenticated(req *http.Request) bool {
+	ok, _ := req.Context().Value(authenticatedKey{}).(bool)
+	return ok
+}
+
+func (s *Server) listReleases(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	var releases []*release
+	for _, rel := range repo.sortedReleases() {
+		if *rel.data.Draft && !authenticated(req) {
+			continue
+		}
+		releases = append(releases, rel)
+	}
+
+	start, end := paginate(w, req, len(releases))
+
+	page := []*github.RepositoryRelease{}
+	for _, rel := range releases[start:end] {
+		page = append(page, repo.render(rel))
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) createRelease(w http.ResponseWriter, req *http.Request) {
+	var data github.RepositoryRelease
+	if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	if data.TagName == nil || *data.TagName == "" {
+		writeValidationError(w, "Release", "tag_name", "missing_field")
+		return
+	}
+
+	if _, found := repo.releaseByTag(*data.TagName); found {
+		writeValidationError(w, "Release", "tag_name", "already_exists")
+		return
+	}
+
+	if data.GenerateReleaseNotes != nil && *data.GenerateReleaseNotes {
+		notes := fmt.Sprintf("**Full Changelog**: %s", repo.htmlURL("/commits/%s", *data.TagName))
+		if data.Body != nil && *data.Body != "" {
+			notes = *data.Body + "\n\n" + notes
+		}
+		data.Body = github.String(notes)
+	}
+
+	data.ID = nil
+	data.CreatedAt = nil
+	data.PublishedAt = nil
+	data.Author = &github.User{Login: github.String(s.user)}
+
+	writeJSON(w, http.StatusCreated, repo.render(repo.createRelease(data)))
+}
+
+// getRelease serves the release endpoints that share the releases/ prefix:
+// releases/{id}, releases/latest, releases/tags/{tag}, releases/{id}/assets
+// and releases/assets/{id}.
+func (s *Server) getRelease(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	segments := strings.Split(req.PathValue("path"), "/")
+
+	switch {
+	case segments[0] == "tags" && len(segments) > 1:
+		rel, found := repo.releaseByTag(strings.Join(segments[1:], "/"))
+		if !found || *rel.data.Draft {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, repo.render(rel))
+
+	case segments[0] == "assets" && len(segments) == 2:
+		rel, a, found := repo.assetByID(segments[1])
+		if !found || (*rel.data.Draft && !authenticated(req)) {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		if req.Header.Get("Accept") == "application/octet-stream" {
+			http.Redirect(w, req, s.server.URL+fmt.Sprintf("/_storage/assets/%d", *a.data.ID), http.StatusFound)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, repo.renderAsset(rel, a))
+
+	case len(segments) == 1 && segments[0] == "latest":
+		rel, found := repo.latestRelease()
+		if !found {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, repo.render(rel))
+
+	case len(segments) == 1:
+		rel, found := repo.releaseByID(segments[0])
+		if !found || (*rel.data.Draft && !authenticated(req)) {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, repo.render(rel))
+
+	case len(segments) == 2 && segments[1] == "assets":
+		rel, found := repo.releaseByID(segments[0])
+		if !found || (*rel.data.Draft && !authenticated(req)) {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		start, end := paginate(w, req, len(rel.assets))
+
+		assets := []*github.ReleaseAsset{}
+		for _, a := range rel.assets[start:end] {
+			assets = append(assets, repo.renderAsset(rel, a))
+		}
+
+		writeJSON(w, http.StatusOK, assets)
+
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// editRelease serves releases/{id} and releases/assets/{id}.
+func (s *Server) editRelease(w http.ResponseWriter, req *http.Request) {
+	segments := strings.Split(req.PathValue("path"), "/")
+
+	switch {
+	case len(segments) == 1:
+		s.updateRelease(w, req, segments[0])
+	case len(segments) == 2 && segments[0] == "assets":
+		s.updateAsset(w, req, segments[1])
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) updateRelease(w http.ResponseWriter, req *http.Request, id string) {
+	var edit github.RepositoryRelease
+	if err := json.NewDecoder(req.Body).Decode(&edit); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	rel, found := repo.releaseByID(id)
+	if !found {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if edit.TagName != nil && *edit.TagName != *rel.data.TagName {
+		if _, found := repo.releaseByTag(*edit.TagName); found {
+			writeValidationError(w, "Release", "tag_name", "already_exists")
+			return
+		}
+		rel.data.TagName = edit.TagName
+	}
+
+	if edit.TargetCommitish != nil && *edit.TargetCommitish != "" {
+		rel.data.TargetCommitish = edit.TargetCommitish
+	}
+	if edit.Name != nil {
+		rel.data.Name = edit.Name
+	}
+	if edit.Body != nil {
+		rel.data.Body = edit.Body
+	}
+	if edit.Prerelease != nil {
+		rel.data.Prerelease = edit.Prerelease
+	}
+	if edit.Draft != nil {
+		rel.data.Draft = edit.Draft
+	}
+
+	if *rel.data.Draft {
+		rel.data.PublishedAt = nil
+	} else {
+		repo.publish(rel)
+	}
+
+	writeJSON(w, http.StatusOK, repo.render(rel))
+}
+
+func (s *Server) updateAsset(w http.ResponseWriter, req *http.Request, id string) {
+	var edit github.ReleaseAsset
+	if err := json.NewDecoder(req.Body).Decode(&edit); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	rel, a, found := repo.assetByID(id)
+	if !found {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if edit.Name != nil && *edit.Name != *a.data.Name {
+		if rel.assetByName(*edit.Name) != nil {
+			writeValidationError(w, "ReleaseAsset", "name", "already_exists")
+			return
+		}
+		a.data.Name = edit.Name
+	}
+
+	if edit.Label != nil {
+		a.data.Label = edit.Label
+	}
+
+	a.data.UpdatedAt = s.timestamp()
+
+	writeJSON(w, http.StatusOK, repo.renderAsset(rel, a))
+}
+
+func (s *Server) uploadAsset(w http.ResponseWriter, req *http.Request) {
+	content, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Could not read the asset")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	rel, found := repo.releaseByID(req.PathValue("id"))
+	if !found {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	name := req.URL.Query().Get("name")
+	if name == "" {
+		writeValidationError(w, "ReleaseAsset", "name", "missing_field")
+		return
+	}
+
+	if rel.assetByName(name) != nil {
+		writeValidationError(w, "ReleaseAsset", "name", "already_exists")
+		return
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	a := repo.uploadAsset(rel, name, contentType, content)
+
+	writeJSON(w, http.StatusCreated, repo.renderAsset(rel, a))
+}
+
+func (s *Server) deleteAsset(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	rel, a, found := repo.assetByID(req.PathValue("id"))
+	if !found {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	for i, other := range rel.assets {
+		if other == a {
+			rel.assets = append(rel.assets[:i], rel.assets[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getRef(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	name := req.PathValue("ref")
+	object, found := repo.refs[name]
+	if !found {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &github.Reference{
+		Ref: github.String("refs/" + name),
+		URL: github.String(repo.apiURL("/git/refs/%s", name)),
+		Object: &github.GitObject{
+			Type: github.String(object.typ),
+			SHA:  github.String(object.sha),
+			URL:  github.String(repo.objectURL(object)),
+		},
+	})
+}
+
+func (s *Server) getTag(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, ok := s.findRepository(w, req)
+	if !ok {
+		return
+	}
+
+	sha := req.PathValue("sha")
+	tag, found := repo.tags[sha]
+	if !found {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &github.Tag{
+		Tag: github.String(tag.name),
+		SHA: github.String(sha),
+		URL: github.String(repo.apiURL("/git/tags/%s", sha)),
+		Object: &github.GitObject{
+			Type: github.String(tag.target.typ),
+			SHA:  github.String(tag.target.sha),
+			URL:  github.String(repo.objectURL(tag.target)),
+		},
+	})
+}
+
+// archiveLink redirects to the source archive of the ref, like the tarball
+// and zipball endpoints.
+func (s *Server) archiveLink(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		repo, ok := s.findRepository(w, req)
+		if !ok {
+			return
+		}
+
+		ref := req.PathValue("ref")
+		if _, found := repo.resolveRef(ref); !found {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		query := url.Values{
+			"owner":  {repo.owner},
+			"repo":   {repo.name},
+			"ref":    {ref},
+			"format": {format},
+		}
+
+		http.Redirect(w, req, s.server.URL+"/_storage/archive?"+query.Encode(), http.StatusFound)
+	}
+}
+
+func (s *Server) browserDownload(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, found := s.lookup(req.PathValue("owner"), req.PathValue("repo"))
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+
+	rel, found := repo.releaseByTag(req.PathValue("tag"))
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+
+	a := rel.assetByName(req.PathValue("name"))
+	if a == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	http.Redirect(w, req, s.server.URL+fmt.Sprintf("/_storage/assets/%d", *a.data.ID), http.StatusFound)
+}
+
+// storedAsset serves the content of an asset, including range requests.
+func (s *Server) storedAsset(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(req.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	s.mu.Lock()
+	var found bool
+	var a *asset
+	for _, repo := range s.repositories {
+		if _, a, found = repo.asset(id); found {
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", *a.data.ContentType)
+	http.ServeContent(w, req, *a.data.Name, a.data.UpdatedAt.Time, bytes.NewReader(a.content))
+}
+
+func (s *Server) storedArchive(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	s.mu.Lock()
+	repo, found := s.lookup(query.Get("owner"), query.Get("repo"))
+	var files map[string]string
+	if found {
+		files = repo.sourceFiles
+	}
+	s.mu.Unlock()
+
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+
+	ref := query.Get("ref")
+	prefix := repo.name + "-" + strings.ReplaceAll(strings.TrimPrefix(ref, "v"), "/", "-") + "/"
+
+	var archive []byte
+	var err error
+	switch query.Get("format") {
+	case "tar.gz":
+		w.Header().Set("Content-Type", "application/x-gzip")
+		archive, err = tarball(prefix, files)
+	case "zip":
+		w.Header().Set("Content-Type", "application/zip")
+		archive, err = zipball(prefix, files)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(archive))
+}
+
+// findRepository looks up the repository named in the request path, writing
+// a 404 if it does not exist. The server lock must be held.
+func (s *Server) findRepository(w http.ResponseWriter, req *http.Request) (*Repository, bool) {
+	repo, found := s.lookup(req.PathValue("owner"), req.PathValue("repo"))
+	if !found {
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+
+	return repo, found
+}
+
+func (r *Repository) releaseByID(id string) (*release, bool) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	return r.release(n)
+}
+
+func (r *Repository) assetByID(id string) (*release, *asset, bool) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	return r.asset(n)
+}
+
+func (rel *release) assetByName(name string) *asset {
+	for _, a := range rel.assets {
+		if *a.data.Name == name {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// resolveRef finds the commit a tag, branch or commit SHA refers to.
+func (r *Repository) resolveRef(ref string) (string, bool) {
+	if commitSHA.MatchString(ref) {
+		return ref, true
+	}
+
+	for _, prefix := range []string{"tags/", "heads/"} {
+		object, found := r.refs[prefix+ref]
+		if !found {
+			continue
+		}
+
+		for object.typ == "tag" {
+			tag, found := r.tags[object.sha]
+			if !found {
+				return "", false
+			}
+			object = tag.target
+		}
+
+		return object.sha, true
+	}
+
+	return "", false
+}
+
+func (r *Repository) objectURL(object gitObject) string {
+	if object.typ == "tag" {
+		return r.apiURL("/git/tags/%s", object.sha)
+	}
+
+	return r.apiURL("/git/commits/%s", object.sha)
+}
+
+// paginate applies the page and per_page parameters to a list of the given
+// length, linking to the next page if there is one.
+func paginate(w http.ResponseWriter, req *http.Request, total int) (int, int) {
+	query := req.URL.Query()
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 30
+	}
+	perPage = min(perPage, 100)
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+
+	if end < total {
+		query.Set("page", strconv.Itoa(page+1))
+		query.Set("per_page", strconv.Itoa(perPage))
+
+		next := url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path, RawQuery: query.Encode()}
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+	}
+
+	return start, end
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+func writeValidationError(w http.ResponseWriter, resource, field, code string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"message": "Validation Failed",
+		"errors": []map[string]string{
+			{"resource": resource, "field": field, "code": code},
+		},
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
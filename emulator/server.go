@@ -0,0 +1,473 @@
+// Package emulator is an in-memory emulation of the parts of the GitHub REST
+// and GraphQL APIs that the resource uses, so that the GitHubClient and the
+// check, in and out binaries can be exercised end to end without network
+// access.
+//
+// Start a server with NewServer, seed it through Repository and point the
+// resource's github_api_url at URL:
+//
+//	server := emulator.NewServer()
+//	defer server.Close()
+//
+//	repo := server.Repository("concourse", "concourse")
+//	release := repo.CreateRelease(github.RepositoryRelease{TagName: github.String("v1.0.0")})
+//	repo.UploadAsset(*release.ID, "example.tgz", []byte("content"))
+package emulator
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Server is a running GitHub API emulator.
+type Server struct {
+	server *httptest.Server
+
+	mu           sync.Mutex
+	repositories map[string]*Repository
+	nextID       int64
+	token        string
+	user         string
+	now          func() time.Time
+}
+
+// NewServer starts an emulator listening on a local port.
+func NewServer() *Server {
+	s := &Server{
+		repositories: map[string]*Repository{},
+		user:         "octocat",
+		now:          time.Now,
+	}
+
+	s.server = httptest.NewServer(s.handler())
+
+	return s
+}
+
+// URL is the REST API endpoint, suitable for github_api_url. The GraphQL
+// endpoint is derived from it the same way as for GitHub Enterprise.
+func (s *Server) URL() string {
+	return s.server.URL + "/"
+}
+
+// GraphQLURL is the GraphQL API endpoint, suitable for github_v4_api_url.
+func (s *Server) GraphQLURL() string {
+	return s.server.URL + "/graphql"
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// RequireToken makes every API request fail with 401 Bad credentials unless
+// it is authenticated with the token. By default any credentials, or none,
+// are accepted.
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetUser sets the login recorded as the author of releases created through
+// the API. Defaults to "octocat".
+func (s *Server) SetUser(login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = login
+}
+
+// SetClock replaces the clock used to timestamp releases and assets.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Repository returns the repository, creating it empty if necessary.
+func (s *Server) Repository(owner, name string) *Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repository(owner, name)
+}
+
+func (s *Server) repository(owner, name string) *Repository {
+	key := owner + "/" + name
+	if r, found := s.repositories[key]; found {
+		return r
+	}
+
+	r := &Repository{
+		server:      s,
+		owner:       owner,
+		name:        name,
+		refs:        map[string]gitObject{},
+		tags:        map[string]annotatedTag{},
+		sourceFiles: map[string]string{"README.md": "# " + name + "\n"},
+	}
+	s.repositories[key] = r
+
+	return r
+}
+
+func (s *Server) lookup(owner, name string) (*Repository, bool) {
+	r, found := s.repositories[owner+"/"+name]
+	return r, found
+}
+
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) timestamp() *github.Timestamp {
+	return &github.Timestamp{Time: s.now().UTC().Truncate(time.Second)}
+}
+
+// Repository holds the releases, assets and tags of an emulated repository.
+// Its methods seed and inspect state directly, bypassing authentication.
+type Repository struct {
+	server *Server
+
+	owner string
+	name  string
+
+	releases []*release
+
+	// refs maps refs such as "tags/v1.0.0" or "heads/main" to the object
+	// they point to; tags maps the SHAs of annotated tag objects to them.
+	refs map[string]gitObject
+	tags map[string]annotatedTag
+
+	sourceFiles map[string]string
+}
+
+type release struct {
+	data   github.RepositoryRelease
+	assets []*asset
+}
+
+type asset struct {
+	data    github.ReleaseAsset
+	content []byte
+}
+
+type gitObject struct {
+	typ string
+	sha string
+}
+
+type annotatedTag struct {
+	name   string
+	target gitObject
+}
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// CreateRelease adds a release as if it was created through the API. Unset
+// timestamps default to now, and publishing a release creates its tag if it
+// does not exist yet.
+func (r *Repository) CreateRelease(rel github.RepositoryRelease) *github.RepositoryRelease {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	if rel.Author == nil {
+		rel.Author = &github.User{Login: github.String(r.server.user)}
+	}
+
+	return r.render(r.createRelease(rel))
+}
+
+// Releases returns every release, newest first.
+func (r *Repository) Releases() []*github.RepositoryRelease {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	var releases []*github.RepositoryRelease
+	for _, rel := range r.sortedReleases() {
+		releases = append(releases, r.render(rel))
+	}
+
+	return releases
+}
+
+// UploadAsset adds an asset to the release as if it was uploaded through the
+// API.
+func (r *Repository) UploadAsset(releaseID int64, name string, content []byte) *github.ReleaseAsset {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	rel, found := r.release(releaseID)
+	if !found {
+		panic(fmt.Sprintf("emulator: release %d does not exist", releaseID))
+	}
+
+	return r.renderAsset(rel, r.uploadAsset(rel, name, "application/octet-stream", content))
+}
+
+// Assets returns the assets of the release in upload order.
+func (r *Repository) Assets(releaseID int64) []*github.ReleaseAsset {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	rel, found := r.release(releaseID)
+	if !found {
+		return nil
+	}
+
+	var assets []*github.ReleaseAsset
+	for _, a := range rel.assets {
+		assets = append(assets, r.renderAsset(rel, a))
+	}
+
+	return assets
+}
+
+// AssetContent returns the content of the asset.
+func (r *Repository) AssetContent(assetID int64) ([]byte, bool) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	_, a, found := r.asset(assetID)
+	if !found {
+		return nil, false
+	}
+
+	return a.content, true
+}
+
+// CreateTag creates a lightweight tag pointing at the commit.
+func (r *Repository) CreateTag(tag, commit string) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.refs["tags/"+tag] = gitObject{typ: "commit", sha: commit}
+}
+
+// CreateAnnotatedTag creates an annotated tag object with the given SHA
+// pointing at the commit, and a tag ref pointing at the tag object.
+func (r *Repository) CreateAnnotatedTag(tag, tagSHA, commit string) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.tags[tagSHA] = annotatedTag{name: tag, target: gitObject{typ: "commit", sha: commit}}
+	r.refs["tags/"+tag] = gitObject{typ: "tag", sha: tagSHA}
+}
+
+// CreateBranch points the branch at the commit.
+func (r *Repository) CreateBranch(branch, commit string) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.refs["heads/"+branch] = gitObject{typ: "commit", sha: commit}
+}
+
+// SetSourceFiles sets the files, by path, contained in the source archives
+// of every ref. Defaults to a single README.md.
+func (r *Repository) SetSourceFiles(files map[string]string) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.sourceFiles = files
+}
+
+func (r *Repository) createRelease(data github.RepositoryRelease) *release {
+	data.ID = github.Int64(r.server.id())
+	data.GenerateReleaseNotes = nil
+
+	if data.Draft == nil {
+		data.Draft = github.Bool(false)
+	}
+	if data.Prerelease == nil {
+		data.Prerelease = github.Bool(false)
+	}
+	if data.Name == nil {
+		data.Name = github.String("")
+	}
+	if data.Body == nil {
+		data.Body = github.String("")
+	}
+	if data.TargetCommitish == nil || *data.TargetCommitish == "" {
+		data.TargetCommitish = github.String("main")
+	}
+	if data.CreatedAt == nil {
+		data.CreatedAt = r.server.timestamp()
+	}
+
+	rel := &release{data: data}
+	r.releases = append(r.releases, rel)
+
+	if !*data.Draft {
+		r.publish(rel)
+	}
+
+	return rel
+}
+
+// publish timestamps the release and creates its tag from the target
+// commitish if it does not exist yet, as GitHub does.
+func (r *Repository) publish(rel *release) {
+	if rel.data.PublishedAt == nil {
+		rel.data.PublishedAt = r.server.timestamp()
+	}
+
+	if rel.data.TagName == nil || *rel.data.TagName == "" {
+		return
+	}
+
+	ref := "tags/" + *rel.data.TagName
+	if _, found := r.refs[ref]; !found {
+		r.refs[ref] = gitObject{typ: "commit", sha: r.resolveCommitish(*rel.data.TargetCommitish)}
+	}
+}
+
+func (r *Repository) resolveCommitish(commitish string) string {
+	if commitSHA.MatchString(commitish) {
+		return commitish
+	}
+
+	if head, found := r.refs["heads/"+commitish]; found {
+		return head.sha
+	}
+
+	sum := sha1.Sum([]byte(r.owner + "/" + r.name + "@" + commitish))
+	commit := hex.EncodeToString(sum[:])
+	r.refs["heads/"+commitish] = gitObject{typ: "commit", sha: commit}
+
+	return commit
+}
+
+func (r *Repository) uploadAsset(rel *release, name, contentType string, content []byte) *asset {
+	sum := sha256.Sum256(content)
+	now := r.server.timestamp()
+
+	a := &asset{
+		data: github.ReleaseAsset{
+			ID:            github.Int64(r.server.id()),
+			Name:          github.String(name),
+			Label:         github.String(""),
+			State:         github.String("uploaded"),
+			ContentType:   github.String(contentType),
+			Size:          github.Int(len(content)),
+			DownloadCount: github.Int(0),
+			Digest:        github.String("sha256:" + hex.EncodeToString(sum[:])),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			Uploader:      &github.User{Login: github.String(r.server.user)},
+		},
+		content: content,
+	}
+	rel.assets = append(rel.assets, a)
+
+	return a
+}
+
+func (r *Repository) release(id int64) (*release, bool) {
+	for _, rel := range r.releases {
+		if *rel.data.ID == id {
+			return rel, true
+		}
+	}
+
+	return nil, false
+}
+
+func (r *Repository) releaseByTag(tag string) (*release, bool) {
+	for _, rel := range r.releases {
+		if rel.data.TagName != nil && *rel.data.TagName == tag {
+			return rel, true
+		}
+	}
+
+	return nil, false
+}
+
+// latestRelease returns the newest release that is neither a draft nor a
+// prerelease, which is the one GitHub marks as the latest by default.
+func (r *Repository) latestRelease() (*release, bool) {
+	for _, rel := range r.sortedReleases() {
+		if !*rel.data.Draft && !*rel.data.Prerelease {
+			return rel, true
+		}
+	}
+
+	return nil, false
+}
+
+func (r *Repository) asset(id int64) (*release, *asset, bool) {
+	for _, rel := range r.releases {
+		for _, a := range rel.assets {
+			if *a.data.ID == id {
+				return rel, a, true
+			}
+		}
+	}
+
+	return nil, nil, false
+}
+
+// sortedReleases orders the releases newest first, like GitHub lists them.
+func (r *Repository) sortedReleases() []*release {
+	releases := append([]*release(nil), r.releases...)
+	sort.SliceStable(releases, func(i, j int) bool {
+		ci, cj := releases[i].data.CreatedAt.Time, releases[j].data.CreatedAt.Time
+		if !ci.Equal(cj) {
+			return ci.After(cj)
+		}
+		return *releases[i].data.ID > *releases[j].data.ID
+	})
+
+	return releases
+}
+
+func (r *Repository) apiURL(format string, args ...any) string {
+	return r.server.server.URL + fmt.Sprintf("/repos/%s/%s", r.owner, r.name) + fmt.Sprintf(format, args...)
+}
+
+func (r *Repository) htmlURL(format string, args ...any) string {
+	return r.server.server.URL + fmt.Sprintf("/%s/%s", r.owner, r.name) + fmt.Sprintf(format, args...)
+}
+
+func (r *Repository) render(rel *release) *github.RepositoryRelease {
+	data := rel.data
+	tag := ""
+	if data.TagName != nil {
+		tag = *data.TagName
+	}
+
+	data.URL = github.String(r.apiURL("/releases/%d", *data.ID))
+	data.AssetsURL = github.String(r.apiURL("/releases/%d/assets", *data.ID))
+	data.UploadURL = github.String(r.apiURL("/releases/%d/assets{?name,label}", *data.ID))
+	data.HTMLURL = github.String(r.htmlURL("/releases/tag/%s", tag))
+	data.TarballURL = github.String(r.apiURL("/tarball/%s", tag))
+	data.ZipballURL = github.String(r.apiURL("/zipball/%s", tag))
+
+	data.Assets = []*github.ReleaseAsset{}
+	for _, a := range rel.assets {
+		data.Assets = append(data.Assets, r.renderAsset(rel, a))
+	}
+
+	return &data
+}
+
+func (r *Repository) renderAsset(rel *release, a *asset) *github.ReleaseAsset {
+	data := a.data
+
+	tag := ""
+	if rel.data.TagName != nil {
+		tag = *rel.data.TagName
+	}
+
+	data.URL = github.String(r.apiURL("/releases/assets/%d", *data.ID))
+	data.BrowserDownloadURL = github.String(r.htmlURL("/releases/download/%s/%s", tag, *data.Name))
+
+	return &data
+}
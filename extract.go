@@ -0,0 +1,359 @@
+package resource
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// archiveFormats maps the archive formats that can be extracted to the file
+// name suffixes they are recognised by.
+var archiveFormats = []struct {
+	format   string
+	suffixes []string
+}{
+	{"tar.gz", []string{".tar.gz", ".tgz"}},
+	{"tar.xz", []string{".tar.xz", ".txz"}},
+	{"tar.zst", []string{".tar.zst", ".tzst"}},
+	{"tar", []string{".tar"}},
+	{"zip", []string{".zip"}},
+}
+
+func archiveFormat(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for _, f := range archiveFormats {
+		for _, suffix := range f.suffixes {
+			if strings.HasSuffix(name, suffix) {
+				return f.format, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func validateExtractParams(extract []ExtractParams) error {
+	for _, e := range extract {
+		if _, err := filepath.Match(e.Glob, ""); err != nil || e.Glob == "" {
+			return fmt.Errorf("invalid extract glob '%s'", e.Glob)
+		}
+
+		if e.Into != "" && !filepath.IsLocal(e.Into) {
+			return fmt.Errorf("invalid extract destination '%s': must be a relative path within the destination directory", e.Into)
+		}
+
+		if e.StripComponents < 0 {
+			return fmt.Errorf("invalid strip_components for '%s': must not be negative", e.Glob)
+		}
+
+		if e.Format != "" {
+			found := false
+			for _, f := range archiveFormats {
+				found = found || f.format == e.Format
+			}
+
+			if !found {
+				return fmt.Errorf("unsupported archive format '%s'", e.Format)
+			}
+		}
+	}
+
+	return nil
+}
+
+// extractRule returns the first of the extract params whose glob matches the
+// name of the file.
+func extractRule(extract []ExtractParams, name string) (ExtractParams, bool) {
+	for _, e := range extract {
+		if matched, _ := filepath.Match(e.Glob, name); matched {
+			return e, true
+		}
+	}
+
+	return ExtractParams{}, false
+}
+
+// extractArchive extracts the archive into destDir. Entries may not be
+// written outside of destDir, whether through their own paths, through
+// symlinks in the archive or through symlinks that already exist.
+func extractArchive(archivePath, destDir string, params ExtractParams) error {
+	format := params.Format
+	if format == "" {
+		var ok bool
+		format, ok = archiveFormat(filepath.Base(archivePath))
+		if !ok {
+			return fmt.Errorf("could not determine the archive format of '%s'", filepath.Base(archivePath))
+		}
+	}
+
+	err := os.MkdirAll(destDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	root, err := os.OpenRoot(destDir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	x := &extractor{root: root, stripComponents: params.StripComponents}
+
+	if format == "zip" {
+		return x.extractZip(archivePath)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	switch format {
+	case "tar.gz":
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case "tar.xz":
+		r, err = xz.NewReader(file)
+		if err != nil {
+			return err
+		}
+	case "tar.zst":
+		zr, err := zstd.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	return x.extractTar(r)
+}
+
+type extractor struct {
+	root            *os.Root
+	stripComponents int
+}
+
+func (x *extractor) extractTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name, ok, err := x.entryPath(header.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = x.root.MkdirAll(name, 0755)
+		case tar.TypeReg:
+			err = x.writeFile(name, tr, header.FileInfo().Mode())
+		case tar.TypeSymlink:
+			err = x.symlink(header.Linkname, name)
+		case tar.TypeLink:
+			var target string
+			target, ok, err = x.entryPath(header.Linkname)
+			if err == nil && !ok {
+				err = fmt.Errorf("invalid hard link '%s': target '%s' is stripped", header.Name, header.Linkname)
+			}
+			if err == nil {
+				err = x.link(target, name)
+			}
+		default:
+			// Devices, FIFOs and extended headers have no place in a
+			// release asset.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (x *extractor) extractZip(archivePath string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		name, ok, err := x.entryPath(f.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.root.MkdirAll(name, 0755)
+		case mode&os.ModeSymlink != 0:
+			err = x.extractZipSymlink(f, name)
+		case mode.IsRegular():
+			err = x.extractZipFile(f, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (x *extractor) extractZipFile(f *zip.File, name string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return x.writeFile(name, rc, f.Mode())
+}
+
+func (x *extractor) extractZipSymlink(f *zip.File, name string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	target, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return err
+	}
+
+	return x.symlink(string(target), name)
+}
+
+// entryPath returns the path an entry is extracted to, relative to the
+// destination, after stripping leading components. It reports false for
+// entries that are stripped entirely.
+func (x *extractor) entryPath(name string) (string, bool, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(cleaned) || !filepath.IsLocal(filepath.FromSlash(cleaned)) {
+		return "", false, fmt.Errorf("invalid archive entry '%s': path escapes the destination", name)
+	}
+
+	parts := strings.Split(cleaned, "/")
+	if len(parts) <= x.stripComponents {
+		return "", false, nil
+	}
+
+	return filepath.Join(parts[x.stripComponents:]...), true, nil
+}
+
+func (x *extractor) writeFile(name string, r io.Reader, mode os.FileMode) error {
+	err := x.clear(name)
+	if err != nil {
+		return err
+	}
+
+	out, err := x.root.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, r)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// symlink creates a symlink whose target must resolve within the
+// destination, so that nothing reading the extracted files is led out of it.
+func (x *extractor) symlink(target, name string) error {
+	err := x.clear(name)
+	if err != nil {
+		return err
+	}
+
+	if filepath.IsAbs(target) || path.IsAbs(target) {
+		return fmt.Errorf("invalid symlink '%s': target '%s' is absolute", name, target)
+	}
+
+	// Walk the target from the directory of the symlink. Going up from a
+	// symlink would leave the directory it points to rather than the one it
+	// is in, so each step up must be out of a real directory.
+	current := filepath.Dir(name)
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		switch part {
+		case "", ".":
+		case "..":
+			info, err := x.root.Lstat(current)
+			if current == "." || err != nil || !info.IsDir() {
+				return fmt.Errorf("invalid symlink '%s': target '%s' escapes the destination", name, target)
+			}
+			current = filepath.Dir(current)
+		default:
+			current = filepath.Join(current, part)
+		}
+	}
+
+	return x.root.Symlink(target, name)
+}
+
+func (x *extractor) link(target, name string) error {
+	err := x.clear(name)
+	if err != nil {
+		return err
+	}
+
+	return x.root.Link(target, name)
+}
+
+// clear makes way for an entry, creating its parent directories and
+// removing any file or symlink in its place rather than writing through it.
+// Directories are never replaced, so that symlinks checked against them
+// keep resolving the same way.
+func (x *extractor) clear(name string) error {
+	err := x.root.MkdirAll(filepath.Dir(name), 0755)
+	if err != nil {
+		return err
+	}
+
+	info, err := x.root.Lstat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("could not extract '%s': a directory is in the way", name)
+	}
+
+	return x.root.Remove(name)
+}
@@ -2,20 +2,21 @@
 package fakes
 
 import (
+	"context"
 	"io"
 	"net/url"
-	"os"
 	"sync"
 
 	resource "github.com/concourse/github-release-resource"
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 )
 
 type FakeGitHub struct {
-	CreateReleaseStub        func(github.RepositoryRelease) (*github.RepositoryRelease, error)
+	CreateReleaseStub        func(context.Context, github.RepositoryRelease) (*github.RepositoryRelease, error)
 	createReleaseMutex       sync.RWMutex
 	createReleaseArgsForCall []struct {
-		arg1 github.RepositoryRelease
+		arg1 context.Context
+		arg2 github.RepositoryRelease
 	}
 	createReleaseReturns struct {
 		result1 *github.RepositoryRelease
@@ -25,10 +26,11 @@ type FakeGitHub struct {
 		result1 *github.RepositoryRelease
 		result2 error
 	}
-	DeleteReleaseAssetStub        func(github.ReleaseAsset) error
+	DeleteReleaseAssetStub        func(context.Context, github.ReleaseAsset) error
 	deleteReleaseAssetMutex       sync.RWMutex
 	deleteReleaseAssetArgsForCall []struct {
-		arg1 github.ReleaseAsset
+		arg1 context.Context
+		arg2 github.ReleaseAsset
 	}
 	deleteReleaseAssetReturns struct {
 		result1 error
@@ -36,10 +38,12 @@ type FakeGitHub struct {
 	deleteReleaseAssetReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DownloadReleaseAssetStub        func(github.ReleaseAsset) (io.ReadCloser, error)
+	DownloadReleaseAssetStub        func(context.Context, github.ReleaseAsset, int64) (io.ReadCloser, error)
 	downloadReleaseAssetMutex       sync.RWMutex
 	downloadReleaseAssetArgsForCall []struct {
-		arg1 github.ReleaseAsset
+		arg1 context.Context
+		arg2 github.ReleaseAsset
+		arg3 int64
 	}
 	downloadReleaseAssetReturns struct {
 		result1 io.ReadCloser
@@ -49,10 +53,24 @@ type FakeGitHub struct {
 		result1 io.ReadCloser
 		result2 error
 	}
-	GetReleaseStub        func(int) (*github.RepositoryRelease, error)
+	GetLatestReleaseStub        func(context.Context) (*github.RepositoryRelease, error)
+	getLatestReleaseMutex       sync.RWMutex
+	getLatestReleaseArgsForCall []struct {
+		arg1 context.Context
+	}
+	getLatestReleaseReturns struct {
+		result1 *github.RepositoryRelease
+		result2 error
+	}
+	getLatestReleaseReturnsOnCall map[int]struct {
+		result1 *github.RepositoryRelease
+		result2 error
+	}
+	GetReleaseStub        func(context.Context, int) (*github.RepositoryRelease, error)
 	getReleaseMutex       sync.RWMutex
 	getReleaseArgsForCall []struct {
-		arg1 int
+		arg1 context.Context
+		arg2 int
 	}
 	getReleaseReturns struct {
 		result1 *github.RepositoryRelease
@@ -62,10 +80,11 @@ type FakeGitHub struct {
 		result1 *github.RepositoryRelease
 		result2 error
 	}
-	GetReleaseByTagStub        func(string) (*github.RepositoryRelease, error)
+	GetReleaseByTagStub        func(context.Context, string) (*github.RepositoryRelease, error)
 	getReleaseByTagMutex       sync.RWMutex
 	getReleaseByTagArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	getReleaseByTagReturns struct {
 		result1 *github.RepositoryRelease
@@ -75,10 +94,11 @@ type FakeGitHub struct {
 		result1 *github.RepositoryRelease
 		result2 error
 	}
-	GetTarballLinkStub        func(string) (*url.URL, error)
+	GetTarballLinkStub        func(context.Context, string) (*url.URL, error)
 	getTarballLinkMutex       sync.RWMutex
 	getTarballLinkArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	getTarballLinkReturns struct {
 		result1 *url.URL
@@ -88,10 +108,11 @@ type FakeGitHub struct {
 		result1 *url.URL
 		result2 error
 	}
-	GetZipballLinkStub        func(string) (*url.URL, error)
+	GetZipballLinkStub        func(context.Context, string) (*url.URL, error)
 	getZipballLinkMutex       sync.RWMutex
 	getZipballLinkArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	getZipballLinkReturns struct {
 		result1 *url.URL
@@ -101,10 +122,26 @@ type FakeGitHub struct {
 		result1 *url.URL
 		result2 error
 	}
-	ListReleaseAssetsStub        func(github.RepositoryRelease) ([]*github.ReleaseAsset, error)
+	IsCommitReachableStub        func(context.Context, string, string) (bool, error)
+	isCommitReachableMutex       sync.RWMutex
+	isCommitReachableArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	isCommitReachableReturns struct {
+		result1 bool
+		result2 error
+	}
+	isCommitReachableReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	ListReleaseAssetsStub        func(context.Context, github.RepositoryRelease) ([]*github.ReleaseAsset, error)
 	listReleaseAssetsMutex       sync.RWMutex
 	listReleaseAssetsArgsForCall []struct {
-		arg1 github.RepositoryRelease
+		arg1 context.Context
+		arg2 github.RepositoryRelease
 	}
 	listReleaseAssetsReturns struct {
 		result1 []*github.ReleaseAsset
@@ -114,9 +151,10 @@ type FakeGitHub struct {
 		result1 []*github.ReleaseAsset
 		result2 error
 	}
-	ListReleasesStub        func() ([]*github.RepositoryRelease, error)
+	ListReleasesStub        func(context.Context) ([]*github.RepositoryRelease, error)
 	listReleasesMutex       sync.RWMutex
 	listReleasesArgsForCall []struct {
+		arg1 context.Context
 	}
 	listReleasesReturns struct {
 		result1 []*github.RepositoryRelease
@@ -126,10 +164,11 @@ type FakeGitHub struct {
 		result1 []*github.RepositoryRelease
 		result2 error
 	}
-	ResolveTagToCommitSHAStub        func(string) (string, error)
+	ResolveTagToCommitSHAStub        func(context.Context, string) (string, error)
 	resolveTagToCommitSHAMutex       sync.RWMutex
 	resolveTagToCommitSHAArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	resolveTagToCommitSHAReturns struct {
 		result1 string
@@ -139,10 +178,11 @@ type FakeGitHub struct {
 		result1 string
 		result2 error
 	}
-	UpdateReleaseStub        func(github.RepositoryRelease) (*github.RepositoryRelease, error)
+	UpdateReleaseStub        func(context.Context, github.RepositoryRelease) (*github.RepositoryRelease, error)
 	updateReleaseMutex       sync.RWMutex
 	updateReleaseArgsForCall []struct {
-		arg1 github.RepositoryRelease
+		arg1 context.Context
+		arg2 github.RepositoryRelease
 	}
 	updateReleaseReturns struct {
 		result1 *github.RepositoryRelease
@@ -152,12 +192,27 @@ type FakeGitHub struct {
 		result1 *github.RepositoryRelease
 		result2 error
 	}
-	UploadReleaseAssetStub        func(github.RepositoryRelease, string, *os.File) error
+	UpdateReleaseAssetStub        func(context.Context, github.ReleaseAsset) (*github.ReleaseAsset, error)
+	updateReleaseAssetMutex       sync.RWMutex
+	updateReleaseAssetArgsForCall []struct {
+		arg1 context.Context
+		arg2 github.ReleaseAsset
+	}
+	updateReleaseAssetReturns struct {
+		result1 *github.ReleaseAsset
+		result2 error
+	}
+	updateReleaseAssetReturnsOnCall map[int]struct {
+		result1 *github.ReleaseAsset
+		result2 error
+	}
+	UploadReleaseAssetStub        func(context.Context, github.RepositoryRelease, string, resource.AssetFile) error
 	uploadReleaseAssetMutex       sync.RWMutex
 	uploadReleaseAssetArgsForCall []struct {
-		arg1 github.RepositoryRelease
-		arg2 string
-		arg3 *os.File
+		arg1 context.Context
+		arg2 github.RepositoryRelease
+		arg3 string
+		arg4 resource.AssetFile
 	}
 	uploadReleaseAssetReturns struct {
 		result1 error
@@ -169,18 +224,19 @@ type FakeGitHub struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeGitHub) CreateRelease(arg1 github.RepositoryRelease) (*github.RepositoryRelease, error) {
+func (fake *FakeGitHub) CreateRelease(arg1 context.Context, arg2 github.RepositoryRelease) (*github.RepositoryRelease, error) {
 	fake.createReleaseMutex.Lock()
 	ret, specificReturn := fake.createReleaseReturnsOnCall[len(fake.createReleaseArgsForCall)]
 	fake.createReleaseArgsForCall = append(fake.createReleaseArgsForCall, struct {
-		arg1 github.RepositoryRelease
-	}{arg1})
+		arg1 context.Context
+		arg2 github.RepositoryRelease
+	}{arg1, arg2})
 	stub := fake.CreateReleaseStub
 	fakeReturns := fake.createReleaseReturns
-	fake.recordInvocation("CreateRelease", []interface{}{arg1})
+	fake.recordInvocation("CreateRelease", []interface{}{arg1, arg2})
 	fake.createReleaseMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -194,17 +250,17 @@ func (fake *FakeGitHub) CreateReleaseCallCount() int {
 	return len(fake.createReleaseArgsForCall)
 }
 
-func (fake *FakeGitHub) CreateReleaseCalls(stub func(github.RepositoryRelease) (*github.RepositoryRelease, error)) {
+func (fake *FakeGitHub) CreateReleaseCalls(stub func(context.Context, github.RepositoryRelease) (*github.RepositoryRelease, error)) {
 	fake.createReleaseMutex.Lock()
 	defer fake.createReleaseMutex.Unlock()
 	fake.CreateReleaseStub = stub
 }
 
-func (fake *FakeGitHub) CreateReleaseArgsForCall(i int) github.RepositoryRelease {
+func (fake *FakeGitHub) CreateReleaseArgsForCall(i int) (context.Context, github.RepositoryRelease) {
 	fake.createReleaseMutex.RLock()
 	defer fake.createReleaseMutex.RUnlock()
 	argsForCall := fake.createReleaseArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) CreateReleaseReturns(result1 *github.RepositoryRelease, result2 error) {
@@ -233,18 +289,19 @@ func (fake *FakeGitHub) CreateReleaseReturnsOnCall(i int, result1 *github.Reposi
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) DeleteReleaseAsset(arg1 github.ReleaseAsset) error {
+func (fake *FakeGitHub) DeleteReleaseAsset(arg1 context.Context, arg2 github.ReleaseAsset) error {
 	fake.deleteReleaseAssetMutex.Lock()
 	ret, specificReturn := fake.deleteReleaseAssetReturnsOnCall[len(fake.deleteReleaseAssetArgsForCall)]
 	fake.deleteReleaseAssetArgsForCall = append(fake.deleteReleaseAssetArgsForCall, struct {
-		arg1 github.ReleaseAsset
-	}{arg1})
+		arg1 context.Context
+		arg2 github.ReleaseAsset
+	}{arg1, arg2})
 	stub := fake.DeleteReleaseAssetStub
 	fakeReturns := fake.deleteReleaseAssetReturns
-	fake.recordInvocation("DeleteReleaseAsset", []interface{}{arg1})
+	fake.recordInvocation("DeleteReleaseAsset", []interface{}{arg1, arg2})
 	fake.deleteReleaseAssetMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -258,17 +315,17 @@ func (fake *FakeGitHub) DeleteReleaseAssetCallCount() int {
 	return len(fake.deleteReleaseAssetArgsForCall)
 }
 
-func (fake *FakeGitHub) DeleteReleaseAssetCalls(stub func(github.ReleaseAsset) error) {
+func (fake *FakeGitHub) DeleteReleaseAssetCalls(stub func(context.Context, github.ReleaseAsset) error) {
 	fake.deleteReleaseAssetMutex.Lock()
 	defer fake.deleteReleaseAssetMutex.Unlock()
 	fake.DeleteReleaseAssetStub = stub
 }
 
-func (fake *FakeGitHub) DeleteReleaseAssetArgsForCall(i int) github.ReleaseAsset {
+func (fake *FakeGitHub) DeleteReleaseAssetArgsForCall(i int) (context.Context, github.ReleaseAsset) {
 	fake.deleteReleaseAssetMutex.RLock()
 	defer fake.deleteReleaseAssetMutex.RUnlock()
 	argsForCall := fake.deleteReleaseAssetArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) DeleteReleaseAssetReturns(result1 error) {
@@ -294,18 +351,20 @@ func (fake *FakeGitHub) DeleteReleaseAssetReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGitHub) DownloadReleaseAsset(arg1 github.ReleaseAsset) (io.ReadCloser, error) {
+func (fake *FakeGitHub) DownloadReleaseAsset(arg1 context.Context, arg2 github.ReleaseAsset, arg3 int64) (io.ReadCloser, error) {
 	fake.downloadReleaseAssetMutex.Lock()
 	ret, specificReturn := fake.downloadReleaseAssetReturnsOnCall[len(fake.downloadReleaseAssetArgsForCall)]
 	fake.downloadReleaseAssetArgsForCall = append(fake.downloadReleaseAssetArgsForCall, struct {
-		arg1 github.ReleaseAsset
-	}{arg1})
+		arg1 context.Context
+		arg2 github.ReleaseAsset
+		arg3 int64
+	}{arg1, arg2, arg3})
 	stub := fake.DownloadReleaseAssetStub
 	fakeReturns := fake.downloadReleaseAssetReturns
-	fake.recordInvocation("DownloadReleaseAsset", []interface{}{arg1})
+	fake.recordInvocation("DownloadReleaseAsset", []interface{}{arg1, arg2, arg3})
 	fake.downloadReleaseAssetMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -319,17 +378,17 @@ func (fake *FakeGitHub) DownloadReleaseAssetCallCount() int {
 	return len(fake.downloadReleaseAssetArgsForCall)
 }
 
-func (fake *FakeGitHub) DownloadReleaseAssetCalls(stub func(github.ReleaseAsset) (io.ReadCloser, error)) {
+func (fake *FakeGitHub) DownloadReleaseAssetCalls(stub func(context.Context, github.ReleaseAsset, int64) (io.ReadCloser, error)) {
 	fake.downloadReleaseAssetMutex.Lock()
 	defer fake.downloadReleaseAssetMutex.Unlock()
 	fake.DownloadReleaseAssetStub = stub
 }
 
-func (fake *FakeGitHub) DownloadReleaseAssetArgsForCall(i int) github.ReleaseAsset {
+func (fake *FakeGitHub) DownloadReleaseAssetArgsForCall(i int) (context.Context, github.ReleaseAsset, int64) {
 	fake.downloadReleaseAssetMutex.RLock()
 	defer fake.downloadReleaseAssetMutex.RUnlock()
 	argsForCall := fake.downloadReleaseAssetArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeGitHub) DownloadReleaseAssetReturns(result1 io.ReadCloser, result2 error) {
@@ -358,18 +417,83 @@ func (fake *FakeGitHub) DownloadReleaseAssetReturnsOnCall(i int, result1 io.Read
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) GetRelease(arg1 int) (*github.RepositoryRelease, error) {
+func (fake *FakeGitHub) GetLatestRelease(arg1 context.Context) (*github.RepositoryRelease, error) {
+	fake.getLatestReleaseMutex.Lock()
+	ret, specificReturn := fake.getLatestReleaseReturnsOnCall[len(fake.getLatestReleaseArgsForCall)]
+	fake.getLatestReleaseArgsForCall = append(fake.getLatestReleaseArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.GetLatestReleaseStub
+	fakeReturns := fake.getLatestReleaseReturns
+	fake.recordInvocation("GetLatestRelease", []interface{}{arg1})
+	fake.getLatestReleaseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitHub) GetLatestReleaseCallCount() int {
+	fake.getLatestReleaseMutex.RLock()
+	defer fake.getLatestReleaseMutex.RUnlock()
+	return len(fake.getLatestReleaseArgsForCall)
+}
+
+func (fake *FakeGitHub) GetLatestReleaseCalls(stub func(context.Context) (*github.RepositoryRelease, error)) {
+	fake.getLatestReleaseMutex.Lock()
+	defer fake.getLatestReleaseMutex.Unlock()
+	fake.GetLatestReleaseStub = stub
+}
+
+func (fake *FakeGitHub) GetLatestReleaseArgsForCall(i int) context.Context {
+	fake.getLatestReleaseMutex.RLock()
+	defer fake.getLatestReleaseMutex.RUnlock()
+	argsForCall := fake.getLatestReleaseArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeGitHub) GetLatestReleaseReturns(result1 *github.RepositoryRelease, result2 error) {
+	fake.getLatestReleaseMutex.Lock()
+	defer fake.getLatestReleaseMutex.Unlock()
+	fake.GetLatestReleaseStub = nil
+	fake.getLatestReleaseReturns = struct {
+		result1 *github.RepositoryRelease
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) GetLatestReleaseReturnsOnCall(i int, result1 *github.RepositoryRelease, result2 error) {
+	fake.getLatestReleaseMutex.Lock()
+	defer fake.getLatestReleaseMutex.Unlock()
+	fake.GetLatestReleaseStub = nil
+	if fake.getLatestReleaseReturnsOnCall == nil {
+		fake.getLatestReleaseReturnsOnCall = make(map[int]struct {
+			result1 *github.RepositoryRelease
+			result2 error
+		})
+	}
+	fake.getLatestReleaseReturnsOnCall[i] = struct {
+		result1 *github.RepositoryRelease
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) GetRelease(arg1 context.Context, arg2 int) (*github.RepositoryRelease, error) {
 	fake.getReleaseMutex.Lock()
 	ret, specificReturn := fake.getReleaseReturnsOnCall[len(fake.getReleaseArgsForCall)]
 	fake.getReleaseArgsForCall = append(fake.getReleaseArgsForCall, struct {
-		arg1 int
-	}{arg1})
+		arg1 context.Context
+		arg2 int
+	}{arg1, arg2})
 	stub := fake.GetReleaseStub
 	fakeReturns := fake.getReleaseReturns
-	fake.recordInvocation("GetRelease", []interface{}{arg1})
+	fake.recordInvocation("GetRelease", []interface{}{arg1, arg2})
 	fake.getReleaseMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -383,17 +507,17 @@ func (fake *FakeGitHub) GetReleaseCallCount() int {
 	return len(fake.getReleaseArgsForCall)
 }
 
-func (fake *FakeGitHub) GetReleaseCalls(stub func(int) (*github.RepositoryRelease, error)) {
+func (fake *FakeGitHub) GetReleaseCalls(stub func(context.Context, int) (*github.RepositoryRelease, error)) {
 	fake.getReleaseMutex.Lock()
 	defer fake.getReleaseMutex.Unlock()
 	fake.GetReleaseStub = stub
 }
 
-func (fake *FakeGitHub) GetReleaseArgsForCall(i int) int {
+func (fake *FakeGitHub) GetReleaseArgsForCall(i int) (context.Context, int) {
 	fake.getReleaseMutex.RLock()
 	defer fake.getReleaseMutex.RUnlock()
 	argsForCall := fake.getReleaseArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) GetReleaseReturns(result1 *github.RepositoryRelease, result2 error) {
@@ -422,18 +546,19 @@ func (fake *FakeGitHub) GetReleaseReturnsOnCall(i int, result1 *github.Repositor
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) GetReleaseByTag(arg1 string) (*github.RepositoryRelease, error) {
+func (fake *FakeGitHub) GetReleaseByTag(arg1 context.Context, arg2 string) (*github.RepositoryRelease, error) {
 	fake.getReleaseByTagMutex.Lock()
 	ret, specificReturn := fake.getReleaseByTagReturnsOnCall[len(fake.getReleaseByTagArgsForCall)]
 	fake.getReleaseByTagArgsForCall = append(fake.getReleaseByTagArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.GetReleaseByTagStub
 	fakeReturns := fake.getReleaseByTagReturns
-	fake.recordInvocation("GetReleaseByTag", []interface{}{arg1})
+	fake.recordInvocation("GetReleaseByTag", []interface{}{arg1, arg2})
 	fake.getReleaseByTagMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -447,17 +572,17 @@ func (fake *FakeGitHub) GetReleaseByTagCallCount() int {
 	return len(fake.getReleaseByTagArgsForCall)
 }
 
-func (fake *FakeGitHub) GetReleaseByTagCalls(stub func(string) (*github.RepositoryRelease, error)) {
+func (fake *FakeGitHub) GetReleaseByTagCalls(stub func(context.Context, string) (*github.RepositoryRelease, error)) {
 	fake.getReleaseByTagMutex.Lock()
 	defer fake.getReleaseByTagMutex.Unlock()
 	fake.GetReleaseByTagStub = stub
 }
 
-func (fake *FakeGitHub) GetReleaseByTagArgsForCall(i int) string {
+func (fake *FakeGitHub) GetReleaseByTagArgsForCall(i int) (context.Context, string) {
 	fake.getReleaseByTagMutex.RLock()
 	defer fake.getReleaseByTagMutex.RUnlock()
 	argsForCall := fake.getReleaseByTagArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) GetReleaseByTagReturns(result1 *github.RepositoryRelease, result2 error) {
@@ -486,18 +611,19 @@ func (fake *FakeGitHub) GetReleaseByTagReturnsOnCall(i int, result1 *github.Repo
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) GetTarballLink(arg1 string) (*url.URL, error) {
+func (fake *FakeGitHub) GetTarballLink(arg1 context.Context, arg2 string) (*url.URL, error) {
 	fake.getTarballLinkMutex.Lock()
 	ret, specificReturn := fake.getTarballLinkReturnsOnCall[len(fake.getTarballLinkArgsForCall)]
 	fake.getTarballLinkArgsForCall = append(fake.getTarballLinkArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.GetTarballLinkStub
 	fakeReturns := fake.getTarballLinkReturns
-	fake.recordInvocation("GetTarballLink", []interface{}{arg1})
+	fake.recordInvocation("GetTarballLink", []interface{}{arg1, arg2})
 	fake.getTarballLinkMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -511,17 +637,17 @@ func (fake *FakeGitHub) GetTarballLinkCallCount() int {
 	return len(fake.getTarballLinkArgsForCall)
 }
 
-func (fake *FakeGitHub) GetTarballLinkCalls(stub func(string) (*url.URL, error)) {
+func (fake *FakeGitHub) GetTarballLinkCalls(stub func(context.Context, string) (*url.URL, error)) {
 	fake.getTarballLinkMutex.Lock()
 	defer fake.getTarballLinkMutex.Unlock()
 	fake.GetTarballLinkStub = stub
 }
 
-func (fake *FakeGitHub) GetTarballLinkArgsForCall(i int) string {
+func (fake *FakeGitHub) GetTarballLinkArgsForCall(i int) (context.Context, string) {
 	fake.getTarballLinkMutex.RLock()
 	defer fake.getTarballLinkMutex.RUnlock()
 	argsForCall := fake.getTarballLinkArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) GetTarballLinkReturns(result1 *url.URL, result2 error) {
@@ -550,18 +676,19 @@ func (fake *FakeGitHub) GetTarballLinkReturnsOnCall(i int, result1 *url.URL, res
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) GetZipballLink(arg1 string) (*url.URL, error) {
+func (fake *FakeGitHub) GetZipballLink(arg1 context.Context, arg2 string) (*url.URL, error) {
 	fake.getZipballLinkMutex.Lock()
 	ret, specificReturn := fake.getZipballLinkReturnsOnCall[len(fake.getZipballLinkArgsForCall)]
 	fake.getZipballLinkArgsForCall = append(fake.getZipballLinkArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.GetZipballLinkStub
 	fakeReturns := fake.getZipballLinkReturns
-	fake.recordInvocation("GetZipballLink", []interface{}{arg1})
+	fake.recordInvocation("GetZipballLink", []interface{}{arg1, arg2})
 	fake.getZipballLinkMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -575,17 +702,17 @@ func (fake *FakeGitHub) GetZipballLinkCallCount() int {
 	return len(fake.getZipballLinkArgsForCall)
 }
 
-func (fake *FakeGitHub) GetZipballLinkCalls(stub func(string) (*url.URL, error)) {
+func (fake *FakeGitHub) GetZipballLinkCalls(stub func(context.Context, string) (*url.URL, error)) {
 	fake.getZipballLinkMutex.Lock()
 	defer fake.getZipballLinkMutex.Unlock()
 	fake.GetZipballLinkStub = stub
 }
 
-func (fake *FakeGitHub) GetZipballLinkArgsForCall(i int) string {
+func (fake *FakeGitHub) GetZipballLinkArgsForCall(i int) (context.Context, string) {
 	fake.getZipballLinkMutex.RLock()
 	defer fake.getZipballLinkMutex.RUnlock()
 	argsForCall := fake.getZipballLinkArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) GetZipballLinkReturns(result1 *url.URL, result2 error) {
@@ -614,18 +741,85 @@ func (fake *FakeGitHub) GetZipballLinkReturnsOnCall(i int, result1 *url.URL, res
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) ListReleaseAssets(arg1 github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+func (fake *FakeGitHub) IsCommitReachable(arg1 context.Context, arg2 string, arg3 string) (bool, error) {
+	fake.isCommitReachableMutex.Lock()
+	ret, specificReturn := fake.isCommitReachableReturnsOnCall[len(fake.isCommitReachableArgsForCall)]
+	fake.isCommitReachableArgsForCall = append(fake.isCommitReachableArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.IsCommitReachableStub
+	fakeReturns := fake.isCommitReachableReturns
+	fake.recordInvocation("IsCommitReachable", []interface{}{arg1, arg2, arg3})
+	fake.isCommitReachableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitHub) IsCommitReachableCallCount() int {
+	fake.isCommitReachableMutex.RLock()
+	defer fake.isCommitReachableMutex.RUnlock()
+	return len(fake.isCommitReachableArgsForCall)
+}
+
+func (fake *FakeGitHub) IsCommitReachableCalls(stub func(context.Context, string, string) (bool, error)) {
+	fake.isCommitReachableMutex.Lock()
+	defer fake.isCommitReachableMutex.Unlock()
+	fake.IsCommitReachableStub = stub
+}
+
+func (fake *FakeGitHub) IsCommitReachableArgsForCall(i int) (context.Context, string, string) {
+	fake.isCommitReachableMutex.RLock()
+	defer fake.isCommitReachableMutex.RUnlock()
+	argsForCall := fake.isCommitReachableArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitHub) IsCommitReachableReturns(result1 bool, result2 error) {
+	fake.isCommitReachableMutex.Lock()
+	defer fake.isCommitReachableMutex.Unlock()
+	fake.IsCommitReachableStub = nil
+	fake.isCommitReachableReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) IsCommitReachableReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isCommitReachableMutex.Lock()
+	defer fake.isCommitReachableMutex.Unlock()
+	fake.IsCommitReachableStub = nil
+	if fake.isCommitReachableReturnsOnCall == nil {
+		fake.isCommitReachableReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isCommitReachableReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) ListReleaseAssets(arg1 context.Context, arg2 github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
 	fake.listReleaseAssetsMutex.Lock()
 	ret, specificReturn := fake.listReleaseAssetsReturnsOnCall[len(fake.listReleaseAssetsArgsForCall)]
 	fake.listReleaseAssetsArgsForCall = append(fake.listReleaseAssetsArgsForCall, struct {
-		arg1 github.RepositoryRelease
-	}{arg1})
+		arg1 context.Context
+		arg2 github.RepositoryRelease
+	}{arg1, arg2})
 	stub := fake.ListReleaseAssetsStub
 	fakeReturns := fake.listReleaseAssetsReturns
-	fake.recordInvocation("ListReleaseAssets", []interface{}{arg1})
+	fake.recordInvocation("ListReleaseAssets", []interface{}{arg1, arg2})
 	fake.listReleaseAssetsMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -639,17 +833,17 @@ func (fake *FakeGitHub) ListReleaseAssetsCallCount() int {
 	return len(fake.listReleaseAssetsArgsForCall)
 }
 
-func (fake *FakeGitHub) ListReleaseAssetsCalls(stub func(github.RepositoryRelease) ([]*github.ReleaseAsset, error)) {
+func (fake *FakeGitHub) ListReleaseAssetsCalls(stub func(context.Context, github.RepositoryRelease) ([]*github.ReleaseAsset, error)) {
 	fake.listReleaseAssetsMutex.Lock()
 	defer fake.listReleaseAssetsMutex.Unlock()
 	fake.ListReleaseAssetsStub = stub
 }
 
-func (fake *FakeGitHub) ListReleaseAssetsArgsForCall(i int) github.RepositoryRelease {
+func (fake *FakeGitHub) ListReleaseAssetsArgsForCall(i int) (context.Context, github.RepositoryRelease) {
 	fake.listReleaseAssetsMutex.RLock()
 	defer fake.listReleaseAssetsMutex.RUnlock()
 	argsForCall := fake.listReleaseAssetsArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) ListReleaseAssetsReturns(result1 []*github.ReleaseAsset, result2 error) {
@@ -678,17 +872,18 @@ func (fake *FakeGitHub) ListReleaseAssetsReturnsOnCall(i int, result1 []*github.
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) ListReleases() ([]*github.RepositoryRelease, error) {
+func (fake *FakeGitHub) ListReleases(arg1 context.Context) ([]*github.RepositoryRelease, error) {
 	fake.listReleasesMutex.Lock()
 	ret, specificReturn := fake.listReleasesReturnsOnCall[len(fake.listReleasesArgsForCall)]
 	fake.listReleasesArgsForCall = append(fake.listReleasesArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.ListReleasesStub
 	fakeReturns := fake.listReleasesReturns
-	fake.recordInvocation("ListReleases", []interface{}{})
+	fake.recordInvocation("ListReleases", []interface{}{arg1})
 	fake.listReleasesMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -702,12 +897,19 @@ func (fake *FakeGitHub) ListReleasesCallCount() int {
 	return len(fake.listReleasesArgsForCall)
 }
 
-func (fake *FakeGitHub) ListReleasesCalls(stub func() ([]*github.RepositoryRelease, error)) {
+func (fake *FakeGitHub) ListReleasesCalls(stub func(context.Context) ([]*github.RepositoryRelease, error)) {
 	fake.listReleasesMutex.Lock()
 	defer fake.listReleasesMutex.Unlock()
 	fake.ListReleasesStub = stub
 }
 
+func (fake *FakeGitHub) ListReleasesArgsForCall(i int) context.Context {
+	fake.listReleasesMutex.RLock()
+	defer fake.listReleasesMutex.RUnlock()
+	argsForCall := fake.listReleasesArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeGitHub) ListReleasesReturns(result1 []*github.RepositoryRelease, result2 error) {
 	fake.listReleasesMutex.Lock()
 	defer fake.listReleasesMutex.Unlock()
@@ -734,18 +936,19 @@ func (fake *FakeGitHub) ListReleasesReturnsOnCall(i int, result1 []*github.Repos
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) ResolveTagToCommitSHA(arg1 string) (string, error) {
+func (fake *FakeGitHub) ResolveTagToCommitSHA(arg1 context.Context, arg2 string) (string, error) {
 	fake.resolveTagToCommitSHAMutex.Lock()
 	ret, specificReturn := fake.resolveTagToCommitSHAReturnsOnCall[len(fake.resolveTagToCommitSHAArgsForCall)]
 	fake.resolveTagToCommitSHAArgsForCall = append(fake.resolveTagToCommitSHAArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.ResolveTagToCommitSHAStub
 	fakeReturns := fake.resolveTagToCommitSHAReturns
-	fake.recordInvocation("ResolveTagToCommitSHA", []interface{}{arg1})
+	fake.recordInvocation("ResolveTagToCommitSHA", []interface{}{arg1, arg2})
 	fake.resolveTagToCommitSHAMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -759,17 +962,17 @@ func (fake *FakeGitHub) ResolveTagToCommitSHACallCount() int {
 	return len(fake.resolveTagToCommitSHAArgsForCall)
 }
 
-func (fake *FakeGitHub) ResolveTagToCommitSHACalls(stub func(string) (string, error)) {
+func (fake *FakeGitHub) ResolveTagToCommitSHACalls(stub func(context.Context, string) (string, error)) {
 	fake.resolveTagToCommitSHAMutex.Lock()
 	defer fake.resolveTagToCommitSHAMutex.Unlock()
 	fake.ResolveTagToCommitSHAStub = stub
 }
 
-func (fake *FakeGitHub) ResolveTagToCommitSHAArgsForCall(i int) string {
+func (fake *FakeGitHub) ResolveTagToCommitSHAArgsForCall(i int) (context.Context, string) {
 	fake.resolveTagToCommitSHAMutex.RLock()
 	defer fake.resolveTagToCommitSHAMutex.RUnlock()
 	argsForCall := fake.resolveTagToCommitSHAArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) ResolveTagToCommitSHAReturns(result1 string, result2 error) {
@@ -798,18 +1001,19 @@ func (fake *FakeGitHub) ResolveTagToCommitSHAReturnsOnCall(i int, result1 string
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) UpdateRelease(arg1 github.RepositoryRelease) (*github.RepositoryRelease, error) {
+func (fake *FakeGitHub) UpdateRelease(arg1 context.Context, arg2 github.RepositoryRelease) (*github.RepositoryRelease, error) {
 	fake.updateReleaseMutex.Lock()
 	ret, specificReturn := fake.updateReleaseReturnsOnCall[len(fake.updateReleaseArgsForCall)]
 	fake.updateReleaseArgsForCall = append(fake.updateReleaseArgsForCall, struct {
-		arg1 github.RepositoryRelease
-	}{arg1})
+		arg1 context.Context
+		arg2 github.RepositoryRelease
+	}{arg1, arg2})
 	stub := fake.UpdateReleaseStub
 	fakeReturns := fake.updateReleaseReturns
-	fake.recordInvocation("UpdateRelease", []interface{}{arg1})
+	fake.recordInvocation("UpdateRelease", []interface{}{arg1, arg2})
 	fake.updateReleaseMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -823,17 +1027,17 @@ func (fake *FakeGitHub) UpdateReleaseCallCount() int {
 	return len(fake.updateReleaseArgsForCall)
 }
 
-func (fake *FakeGitHub) UpdateReleaseCalls(stub func(github.RepositoryRelease) (*github.RepositoryRelease, error)) {
+func (fake *FakeGitHub) UpdateReleaseCalls(stub func(context.Context, github.RepositoryRelease) (*github.RepositoryRelease, error)) {
 	fake.updateReleaseMutex.Lock()
 	defer fake.updateReleaseMutex.Unlock()
 	fake.UpdateReleaseStub = stub
 }
 
-func (fake *FakeGitHub) UpdateReleaseArgsForCall(i int) github.RepositoryRelease {
+func (fake *FakeGitHub) UpdateReleaseArgsForCall(i int) (context.Context, github.RepositoryRelease) {
 	fake.updateReleaseMutex.RLock()
 	defer fake.updateReleaseMutex.RUnlock()
 	argsForCall := fake.updateReleaseArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitHub) UpdateReleaseReturns(result1 *github.RepositoryRelease, result2 error) {
@@ -862,20 +1066,86 @@ func (fake *FakeGitHub) UpdateReleaseReturnsOnCall(i int, result1 *github.Reposi
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) UploadReleaseAsset(arg1 github.RepositoryRelease, arg2 string, arg3 *os.File) error {
+func (fake *FakeGitHub) UpdateReleaseAsset(arg1 context.Context, arg2 github.ReleaseAsset) (*github.ReleaseAsset, error) {
+	fake.updateReleaseAssetMutex.Lock()
+	ret, specificReturn := fake.updateReleaseAssetReturnsOnCall[len(fake.updateReleaseAssetArgsForCall)]
+	fake.updateReleaseAssetArgsForCall = append(fake.updateReleaseAssetArgsForCall, struct {
+		arg1 context.Context
+		arg2 github.ReleaseAsset
+	}{arg1, arg2})
+	stub := fake.UpdateReleaseAssetStub
+	fakeReturns := fake.updateReleaseAssetReturns
+	fake.recordInvocation("UpdateReleaseAsset", []interface{}{arg1, arg2})
+	fake.updateReleaseAssetMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitHub) UpdateReleaseAssetCallCount() int {
+	fake.updateReleaseAssetMutex.RLock()
+	defer fake.updateReleaseAssetMutex.RUnlock()
+	return len(fake.updateReleaseAssetArgsForCall)
+}
+
+func (fake *FakeGitHub) UpdateReleaseAssetCalls(stub func(context.Context, github.ReleaseAsset) (*github.ReleaseAsset, error)) {
+	fake.updateReleaseAssetMutex.Lock()
+	defer fake.updateReleaseAssetMutex.Unlock()
+	fake.UpdateReleaseAssetStub = stub
+}
+
+func (fake *FakeGitHub) UpdateReleaseAssetArgsForCall(i int) (context.Context, github.ReleaseAsset) {
+	fake.updateReleaseAssetMutex.RLock()
+	defer fake.updateReleaseAssetMutex.RUnlock()
+	argsForCall := fake.updateReleaseAssetArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitHub) UpdateReleaseAssetReturns(result1 *github.ReleaseAsset, result2 error) {
+	fake.updateReleaseAssetMutex.Lock()
+	defer fake.updateReleaseAssetMutex.Unlock()
+	fake.UpdateReleaseAssetStub = nil
+	fake.updateReleaseAssetReturns = struct {
+		result1 *github.ReleaseAsset
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) UpdateReleaseAssetReturnsOnCall(i int, result1 *github.ReleaseAsset, result2 error) {
+	fake.updateReleaseAssetMutex.Lock()
+	defer fake.updateReleaseAssetMutex.Unlock()
+	fake.UpdateReleaseAssetStub = nil
+	if fake.updateReleaseAssetReturnsOnCall == nil {
+		fake.updateReleaseAssetReturnsOnCall = make(map[int]struct {
+			result1 *github.ReleaseAsset
+			result2 error
+		})
+	}
+	fake.updateReleaseAssetReturnsOnCall[i] = struct {
+		result1 *github.ReleaseAsset
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) UploadReleaseAsset(arg1 context.Context, arg2 github.RepositoryRelease, arg3 string, arg4 resource.AssetFile) error {
 	fake.uploadReleaseAssetMutex.Lock()
 	ret, specificReturn := fake.uploadReleaseAssetReturnsOnCall[len(fake.uploadReleaseAssetArgsForCall)]
 	fake.uploadReleaseAssetArgsForCall = append(fake.uploadReleaseAssetArgsForCall, struct {
-		arg1 github.RepositoryRelease
-		arg2 string
-		arg3 *os.File
-	}{arg1, arg2, arg3})
+		arg1 context.Context
+		arg2 github.RepositoryRelease
+		arg3 string
+		arg4 resource.AssetFile
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.UploadReleaseAssetStub
 	fakeReturns := fake.uploadReleaseAssetReturns
-	fake.recordInvocation("UploadReleaseAsset", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("UploadReleaseAsset", []interface{}{arg1, arg2, arg3, arg4})
 	fake.uploadReleaseAssetMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -889,17 +1159,17 @@ func (fake *FakeGitHub) UploadReleaseAssetCallCount() int {
 	return len(fake.uploadReleaseAssetArgsForCall)
 }
 
-func (fake *FakeGitHub) UploadReleaseAssetCalls(stub func(github.RepositoryRelease, string, *os.File) error) {
+func (fake *FakeGitHub) UploadReleaseAssetCalls(stub func(context.Context, github.RepositoryRelease, string, resource.AssetFile) error) {
 	fake.uploadReleaseAssetMutex.Lock()
 	defer fake.uploadReleaseAssetMutex.Unlock()
 	fake.UploadReleaseAssetStub = stub
 }
 
-func (fake *FakeGitHub) UploadReleaseAssetArgsForCall(i int) (github.RepositoryRelease, string, *os.File) {
+func (fake *FakeGitHub) UploadReleaseAssetArgsForCall(i int) (context.Context, github.RepositoryRelease, string, resource.AssetFile) {
 	fake.uploadReleaseAssetMutex.RLock()
 	defer fake.uploadReleaseAssetMutex.RUnlock()
 	argsForCall := fake.uploadReleaseAssetArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeGitHub) UploadReleaseAssetReturns(result1 error) {
@@ -934,6 +1204,8 @@ func (fake *FakeGitHub) Invocations() map[string][][]interface{} {
 	defer fake.deleteReleaseAssetMutex.RUnlock()
 	fake.downloadReleaseAssetMutex.RLock()
 	defer fake.downloadReleaseAssetMutex.RUnlock()
+	fake.getLatestReleaseMutex.RLock()
+	defer fake.getLatestReleaseMutex.RUnlock()
 	fake.getReleaseMutex.RLock()
 	defer fake.getReleaseMutex.RUnlock()
 	fake.getReleaseByTagMutex.RLock()
@@ -942,6 +1214,8 @@ func (fake *FakeGitHub) Invocations() map[string][][]interface{} {
 	defer fake.getTarballLinkMutex.RUnlock()
 	fake.getZipballLinkMutex.RLock()
 	defer fake.getZipballLinkMutex.RUnlock()
+	fake.isCommitReachableMutex.RLock()
+	defer fake.isCommitReachableMutex.RUnlock()
 	fake.listReleaseAssetsMutex.RLock()
 	defer fake.listReleaseAssetsMutex.RUnlock()
 	fake.listReleasesMutex.RLock()
@@ -950,6 +1224,8 @@ func (fake *FakeGitHub) Invocations() map[string][][]interface{} {
 	defer fake.resolveTagToCommitSHAMutex.RUnlock()
 	fake.updateReleaseMutex.RLock()
 	defer fake.updateReleaseMutex.RUnlock()
+	fake.updateReleaseAssetMutex.RLock()
+	defer fake.updateReleaseAssetMutex.RUnlock()
 	fake.uploadReleaseAssetMutex.RLock()
 	defer fake.uploadReleaseAssetMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -2,7 +2,9 @@ package resource
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/mitchellh/colorstring"
 )
@@ -15,3 +17,16 @@ func Fatal(doing string, err error) {
 func Sayf(message string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, message, args...)
 }
+
+// syncWriter serialises writes so that lines written concurrently by
+// several goroutines do not interleave.
+type syncWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Write(p)
+}
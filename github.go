@@ -2,66 +2,86 @@ package resource
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -o fakes/fake_git_hub.go . GitHub
 type GitHub interface {
-	ListReleases() ([]*github.RepositoryRelease, error)
-	GetReleaseByTag(tag string) (*github.RepositoryRelease, error)
-	GetRelease(id int) (*github.RepositoryRelease, error)
-	CreateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error)
-	UpdateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error)
-
-	ListReleaseAssets(release github.RepositoryRelease) ([]*github.ReleaseAsset, error)
-	UploadReleaseAsset(release github.RepositoryRelease, name string, file *os.File) error
-	DeleteReleaseAsset(asset github.ReleaseAsset) error
-	DownloadReleaseAsset(asset github.ReleaseAsset) (io.ReadCloser, error)
-
-	GetTarballLink(tag string) (*url.URL, error)
-	GetZipballLink(tag string) (*url.URL, error)
-	ResolveTagToCommitSHA(tag string) (string, error)
+	ListReleases(ctx context.Context) ([]*github.RepositoryRelease, error)
+	GetReleaseByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error)
+	GetRelease(ctx context.Context, id int) (*github.RepositoryRelease, error)
+	GetLatestRelease(ctx context.Context) (*github.RepositoryRelease, error)
+	CreateRelease(ctx context.Context, release github.RepositoryRelease) (*github.RepositoryRelease, error)
+	UpdateRelease(ctx context.Context, release github.RepositoryRelease) (*github.RepositoryRelease, error)
+
+	ListReleaseAssets(ctx context.Context, release github.RepositoryRelease) ([]*github.ReleaseAsset, error)
+	UploadReleaseAsset(ctx context.Context, release github.RepositoryRelease, name string, file AssetFile) error
+	UpdateReleaseAsset(ctx context.Context, asset github.ReleaseAsset) (*github.ReleaseAsset, error)
+	DeleteReleaseAsset(ctx context.Context, asset github.ReleaseAsset) error
+	DownloadReleaseAsset(ctx context.Context, asset github.ReleaseAsset, offset int64) (io.ReadCloser, error)
+
+	GetTarballLink(ctx context.Context, tag string) (*url.URL, error)
+	GetZipballLink(ctx context.Context, tag string) (*url.URL, error)
+	ResolveTagToCommitSHA(ctx context.Context, tag string) (string, error)
+	IsCommitReachable(ctx context.Context, sha string, branch string) (bool, error)
+}
+
+// AssetFile is the content of a release asset being uploaded. It is
+// satisfied by *os.File.
+type AssetFile interface {
+	io.Reader
+	Name() string
+	Stat() (os.FileInfo, error)
 }
 
 type GitHubClient struct {
-	client       *github.Client
-	clientV4     *githubv4.Client
-	isEnterprise bool
+	client         *github.Client
+	clientV4       *githubv4.Client
+	assetClient    *http.Client
+	downloadClient *http.Client
+	isEnterprise   bool
 
 	owner       string
 	repository  string
-	accessToken string
+	tokenSource oauth2.TokenSource
+
+	// tagCommits holds the commits tags point to, as listed along with the
+	// releases through GraphQL, saving ResolveTagToCommitSHA a request.
+	tagCommits   map[string]string
+	tagCommitsMu sync.Mutex
 }
 
 func NewGitHubClient(source Source) (*GitHubClient, error) {
-	httpClient := &http.Client{}
-	ctx := context.TODO()
+	// downloadClient is used for requests that must not carry credentials,
+	// such as following asset redirects to third-party storage.
+	downloadClient, err := newHTTPClient(source)
+	if err != nil {
+		return nil, err
+	}
 
-	if source.Insecure {
-		httpClient.Transport = &http.Transport{
-			Proxy:           http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	httpClient := downloadClient
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+
+	ts, err := tokenSource(ctx, source)
+	if err != nil {
+		return nil, err
 	}
 
-	if source.AccessToken != "" {
-		var err error
-		httpClient, err = oauthClient(ctx, source)
-		if err != nil {
-			return nil, err
-		}
+	if ts != nil {
+		httpClient = oauthClient(ctx, ts)
 	}
 
 	client := github.NewClient(httpClient)
@@ -112,27 +132,38 @@ func NewGitHubClient(source Source) (*GitHubClient, error) {
 		owner = source.User
 	}
 
+	// assetClient makes authenticated asset downloads, leaving redirects to
+	// be followed by downloadClient.
+	assetClient := &http.Client{
+		Transport: httpClient.Transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
 	return &GitHubClient{
-		client:       client,
-		clientV4:     clientV4,
-		isEnterprise: isEnterprise,
-		owner:        owner,
-		repository:   source.Repository,
-		accessToken:  source.AccessToken,
+		client:         client,
+		clientV4:       clientV4,
+		assetClient:    assetClient,
+		downloadClient: downloadClient,
+		isEnterprise:   isEnterprise,
+		owner:          owner,
+		repository:     source.Repository,
+		tokenSource:    ts,
 	}, nil
 }
 
-func (g *GitHubClient) ListReleases() ([]*github.RepositoryRelease, error) {
-	if g.accessToken != "" {
+func (g *GitHubClient) ListReleases(ctx context.Context) ([]*github.RepositoryRelease, error) {
+	if g.tokenSource != nil {
 		if g.isEnterprise {
-			return g.listReleasesV4EnterPrice()
+			return g.listReleasesV4EnterPrice(ctx)
 		}
-		return g.listReleasesV4()
+		return g.listReleasesV4(ctx)
 	}
 	opt := &github.ListOptions{PerPage: 100}
 	var allReleases []*github.RepositoryRelease
 	for {
-		releases, res, err := g.client.Repositories.ListReleases(context.TODO(), g.owner, g.repository, opt)
+		releases, res, err := g.client.Repositories.ListReleases(ctx, g.owner, g.repository, opt)
 		if err != nil {
 			return []*github.RepositoryRelease{}, err
 		}
@@ -150,8 +181,8 @@ func (g *GitHubClient) ListReleases() ([]*github.RepositoryRelease, error) {
 	return allReleases, nil
 }
 
-func (g *GitHubClient) GetReleaseByTag(tag string) (*github.RepositoryRelease, error) {
-	release, res, err := g.client.Repositories.GetReleaseByTag(context.TODO(), g.owner, g.repository, tag)
+func (g *GitHubClient) GetReleaseByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error) {
+	release, res, err := g.client.Repositories.GetReleaseByTag(ctx, g.owner, g.repository, tag)
 	if err != nil {
 		return &github.RepositoryRelease{}, err
 	}
@@ -164,8 +195,8 @@ func (g *GitHubClient) GetReleaseByTag(tag string) (*github.RepositoryRelease, e
 	return release, nil
 }
 
-func (g *GitHubClient) GetRelease(id int) (*github.RepositoryRelease, error) {
-	release, res, err := g.client.Repositories.GetRelease(context.TODO(), g.owner, g.repository, int64(id))
+func (g *GitHubClient) GetRelease(ctx context.Context, id int) (*github.RepositoryRelease, error) {
+	release, res, err := g.client.Repositories.GetRelease(ctx, g.owner, g.repository, int64(id))
 	if err != nil {
 		return &github.RepositoryRelease{}, err
 	}
@@ -178,8 +209,28 @@ func (g *GitHubClient) GetRelease(id int) (*github.RepositoryRelease, error) {
 	return release, nil
 }
 
-func (g *GitHubClient) CreateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error) {
-	createdRelease, res, err := g.client.Repositories.CreateRelease(context.TODO(), g.owner, g.repository, &release)
+// GetLatestRelease returns the release GitHub marks as the latest, or nil if
+// there is none.
+func (g *GitHubClient) GetLatestRelease(ctx context.Context) (*github.RepositoryRelease, error) {
+	release, res, err := g.client.Repositories.GetLatestRelease(ctx, g.owner, g.repository)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	err = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+func (g *GitHubClient) CreateRelease(ctx context.Context, release github.RepositoryRelease) (*github.RepositoryRelease, error) {
+	createdRelease, res, err := g.client.Repositories.CreateRelease(ctx, g.owner, g.repository, &release)
 	if err != nil {
 		return &github.RepositoryRelease{}, err
 	}
@@ -192,12 +243,12 @@ func (g *GitHubClient) CreateRelease(release github.RepositoryRelease) (*github.
 	return createdRelease, nil
 }
 
-func (g *GitHubClient) UpdateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error) {
+func (g *GitHubClient) UpdateRelease(ctx context.Context, release github.RepositoryRelease) (*github.RepositoryRelease, error) {
 	if release.ID == nil {
 		return nil, errors.New("release did not have an ID: has it been saved yet?")
 	}
 
-	updatedRelease, res, err := g.client.Repositories.EditRelease(context.TODO(), g.owner, g.repository, *release.ID, &release)
+	updatedRelease, res, err := g.client.Repositories.EditRelease(ctx, g.owner, g.repository, *release.ID, &release)
 	if err != nil {
 		return &github.RepositoryRelease{}, err
 	}
@@ -210,11 +261,11 @@ func (g *GitHubClient) UpdateRelease(release github.RepositoryRelease) (*github.
 	return updatedRelease, nil
 }
 
-func (g *GitHubClient) ListReleaseAssets(release github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+func (g *GitHubClient) ListReleaseAssets(ctx context.Context, release github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
 	opt := &github.ListOptions{PerPage: 100}
 	var allAssets []*github.ReleaseAsset
 	for {
-		assets, res, err := g.client.Repositories.ListReleaseAssets(context.TODO(), g.owner, g.repository, *release.ID, opt)
+		assets, res, err := g.client.Repositories.ListReleaseAssets(ctx, g.owner, g.repository, *release.ID, opt)
 		if err != nil {
 			return []*github.ReleaseAsset{}, err
 		}
@@ -232,17 +283,25 @@ func (g *GitHubClient) ListReleaseAssets(release github.RepositoryRelease) ([]*g
 	return allAssets, nil
 }
 
-func (g *GitHubClient) UploadReleaseAsset(release github.RepositoryRelease, name string, file *os.File) error {
-	_, res, err := g.client.Repositories.UploadReleaseAsset(
-		context.TODO(),
-		g.owner,
-		g.repository,
-		*release.ID,
-		&github.UploadOptions{
-			Name: name,
-		},
-		file,
-	)
+func (g *GitHubClient) UploadReleaseAsset(ctx context.Context, release github.RepositoryRelease, name string, file AssetFile) error {
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		return errors.New("the asset to upload can't be a directory")
+	}
+
+	u := fmt.Sprintf("repos/%s/%s/releases/%d/assets?name=%s", g.owner, g.repository, *release.ID, url.QueryEscape(name))
+
+	mediaType := mime.TypeByExtension(filepath.Ext(file.Name()))
+
+	req, err := g.client.NewUploadRequest(u, file, stat.Size(), mediaType)
+	if err != nil {
+		return err
+	}
+
+	res, err := g.client.Do(transferContext(ctx), req, nil)
 	if err != nil {
 		return err
 	}
@@ -250,8 +309,32 @@ func (g *GitHubClient) UploadReleaseAsset(release github.RepositoryRelease, name
 	return res.Body.Close()
 }
 
-func (g *GitHubClient) DeleteReleaseAsset(asset github.ReleaseAsset) error {
-	res, err := g.client.Repositories.DeleteReleaseAsset(context.TODO(), g.owner, g.repository, *asset.ID)
+func (g *GitHubClient) UpdateReleaseAsset(ctx context.Context, asset github.ReleaseAsset) (*github.ReleaseAsset, error) {
+	if asset.ID == nil {
+		return nil, errors.New("asset did not have an ID: has it been uploaded yet?")
+	}
+
+	// Only the name and label of an asset can be changed.
+	edit := &github.ReleaseAsset{
+		Name:  asset.Name,
+		Label: asset.Label,
+	}
+
+	updatedAsset, res, err := g.client.Repositories.EditReleaseAsset(ctx, g.owner, g.repository, *asset.ID, edit)
+	if err != nil {
+		return &github.ReleaseAsset{}, err
+	}
+
+	err = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedAsset, nil
+}
+
+func (g *GitHubClient) DeleteReleaseAsset(ctx context.Context, asset github.ReleaseAsset) error {
+	res, err := g.client.Repositories.DeleteReleaseAsset(ctx, g.owner, g.repository, *asset.ID)
 	if err != nil {
 		return err
 	}
@@ -259,27 +342,66 @@ func (g *GitHubClient) DeleteReleaseAsset(asset github.ReleaseAsset) error {
 	return res.Body.Close()
 }
 
-func (g *GitHubClient) DownloadReleaseAsset(asset github.ReleaseAsset) (io.ReadCloser, error) {
-	bodyReader, redirectURL, err := g.client.Repositories.DownloadReleaseAsset(context.TODO(), g.owner, g.repository, *asset.ID, nil)
+// DownloadReleaseAsset returns the content of the asset from offset on. A
+// download is resumed with a Range request if the server supports them;
+// otherwise the content before offset is read and discarded.
+func (g *GitHubClient) DownloadReleaseAsset(ctx context.Context, asset github.ReleaseAsset, offset int64) (io.ReadCloser, error) {
+	ctx = transferContext(ctx)
+
+	u := fmt.Sprintf("repos/%s/%s/releases/assets/%d", g.owner, g.repository, *asset.ID)
+	req, err := g.client.NewRequest("GET", u, nil)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/octet-stream")
+	setRange(req, offset)
 
-	if redirectURL == "" {
-		return bodyReader, err
+	resp, err := g.assetClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
 
-	req, err := g.client.NewRequest("GET", redirectURL, nil)
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		resp.Body.Close()
+
+		redirectURL, err := resp.Location()
+		if err != nil {
+			return nil, err
+		}
+
+		return g.downloadRedirect(ctx, redirectURL, offset)
+	}
+
+	err = github.CheckResponse(resp)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return fromOffset(resp, offset)
+}
+
+// downloadRedirect downloads an asset from the storage GitHub redirected to,
+// which must only be sent credentials if it is GitHub itself.
+func (g *GitHubClient) downloadRedirect(ctx context.Context, redirectURL *url.URL, offset int64) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", redirectURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/octet-stream")
-	if g.accessToken != "" && req.URL.Host == g.client.BaseURL.Host {
-		req.Header.Set("Authorization", "Bearer "+g.accessToken)
+	req.Header.Set("User-Agent", g.client.UserAgent)
+	setRange(req, offset)
+	if g.tokenSource != nil && req.URL.Host == g.client.BaseURL.Host {
+		token, err := g.tokenSource.Token()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
 	}
 
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
+	resp, err := g.downloadClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -289,12 +411,45 @@ func (g *GitHubClient) DownloadReleaseAsset(asset github.ReleaseAsset) (io.ReadC
 		return nil, fmt.Errorf("redirect URL %q responded with bad status code: %d", redirectURL, resp.StatusCode)
 	}
 
+	return fromOffset(resp, offset)
+}
+
+func setRange(req *http.Request, offset int64) {
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+}
+
+// fromOffset returns the body of a download from offset on, skipping the
+// start of the content if the server ignored the Range header.
+func fromOffset(resp *http.Response, offset int64) (io.ReadCloser, error) {
+	if offset == 0 {
+		return resp.Body, nil
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		var start int64
+		_, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start)
+		if err != nil || start != offset {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected Content-Range %q when resuming at byte %d", resp.Header.Get("Content-Range"), offset)
+		}
+
+		return resp.Body, nil
+	}
+
+	_, err := io.CopyN(io.Discard, resp.Body, offset)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
 	return resp.Body, nil
 }
 
-func (g *GitHubClient) GetTarballLink(tag string) (*url.URL, error) {
+func (g *GitHubClient) GetTarballLink(ctx context.Context, tag string) (*url.URL, error) {
 	opt := &github.RepositoryContentGetOptions{Ref: tag}
-	u, res, err := g.client.Repositories.GetArchiveLink(context.TODO(), g.owner, g.repository, github.Tarball, opt, 10)
+	u, res, err := g.client.Repositories.GetArchiveLink(ctx, g.owner, g.repository, github.Tarball, opt, 10)
 	if err != nil {
 		return nil, err
 	}
@@ -302,9 +457,9 @@ func (g *GitHubClient) GetTarballLink(tag string) (*url.URL, error) {
 	return u, nil
 }
 
-func (g *GitHubClient) GetZipballLink(tag string) (*url.URL, error) {
+func (g *GitHubClient) GetZipballLink(ctx context.Context, tag string) (*url.URL, error) {
 	opt := &github.RepositoryContentGetOptions{Ref: tag}
-	u, res, err := g.client.Repositories.GetArchiveLink(context.TODO(), g.owner, g.repository, github.Zipball, opt, 10)
+	u, res, err := g.client.Repositories.GetArchiveLink(ctx, g.owner, g.repository, github.Zipball, opt, 10)
 	if err != nil {
 		return nil, err
 	}
@@ -312,8 +467,15 @@ func (g *GitHubClient) GetZipballLink(tag string) (*url.URL, error) {
 	return u, nil
 }
 
-func (g *GitHubClient) ResolveTagToCommitSHA(tagName string) (string, error) {
-	ref, res, err := g.client.Git.GetRef(context.TODO(), g.owner, g.repository, "tags/"+tagName)
+func (g *GitHubClient) ResolveTagToCommitSHA(ctx context.Context, tagName string) (string, error) {
+	g.tagCommitsMu.Lock()
+	sha, found := g.tagCommits[tagName]
+	g.tagCommitsMu.Unlock()
+	if found {
+		return sha, nil
+	}
+
+	ref, res, err := g.client.Git.GetRef(ctx, g.owner, g.repository, "tags/"+tagName)
 	if err != nil {
 		return "", err
 	}
@@ -333,7 +495,7 @@ func (g *GitHubClient) ResolveTagToCommitSHA(tagName string) (string, error) {
 	maxDepth := 10
 
 	for range maxDepth {
-		tag, res, err := g.client.Git.GetTag(context.TODO(), g.owner, g.repository, currentSHA)
+		tag, res, err := g.client.Git.GetTag(ctx, g.owner, g.repository, currentSHA)
 		if err != nil {
 			return "", fmt.Errorf("could not get tag object %q: %w", currentSHA, err)
 		}
@@ -353,16 +515,62 @@ func (g *GitHubClient) ResolveTagToCommitSHA(tagName string) (string, error) {
 	return "", fmt.Errorf("could not resolve tag %q to commit: exceeded maximum tag chain depth of %d", tagName, maxDepth)
 }
 
-func oauthClient(ctx context.Context, source Source) (*http.Client, error) {
-	ts := oauth2.StaticTokenSource(&oauth2.Token{
-		AccessToken: source.AccessToken,
-	})
+func (g *GitHubClient) cacheTagCommit(tagName, sha string) {
+	g.tagCommitsMu.Lock()
+	defer g.tagCommitsMu.Unlock()
+
+	if g.tagCommits == nil {
+		g.tagCommits = map[string]string{}
+	}
+	g.tagCommits[tagName] = sha
+}
+
+// IsCommitReachable reports whether the commit is in the history of the
+// branch, i.e. the branch is at or ahead of it.
+func (g *GitHubClient) IsCommitReachable(ctx context.Context, sha string, branch string) (bool, error) {
+	comparison, res, err := g.client.Repositories.CompareCommits(ctx, g.owner, g.repository, branch, sha, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return false, fmt.Errorf("could not compare commit %s with branch '%s': %w", sha, branch, err)
+	}
+
+	err = res.Body.Close()
+	if err != nil {
+		return false, err
+	}
+
+	switch comparison.GetStatus() {
+	case "identical", "behind":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func tokenSource(ctx context.Context, source Source) (oauth2.TokenSource, error) {
+	if source.usesGitHubApp() {
+		ts, err := newAppTokenSource(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+
+		return oauth2.ReuseTokenSource(nil, ts), nil
+	}
+
+	if source.AccessToken != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: source.AccessToken,
+		}), nil
+	}
+
+	return nil, nil
+}
 
+func oauthClient(ctx context.Context, ts oauth2.TokenSource) *http.Client {
 	oauthClient := oauth2.NewClient(ctx, ts)
 
 	githubHTTPClient := &http.Client{
 		Transport: oauthClient.Transport,
 	}
 
-	return githubHTTPClient, nil
+	return githubHTTPClient
 }
@@ -0,0 +1,176 @@
+package resource
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const defaultGitHubAPIURL = "https://api.github.com/"
+
+// appTokenSource mints GitHub App installation tokens by signing a JWT with
+// the app's private key and exchanging it at the installation's
+// access_tokens endpoint.
+type appTokenSource struct {
+	ctx context.Context
+
+	appID          int64
+	installationID int64
+	privateKey     *rsa.PrivateKey
+	tokenURL       string
+
+	now func() time.Time
+}
+
+func (s Source) usesGitHubApp() bool {
+	return s.GitHubAppID != 0 || s.GitHubAppInstallationID != 0 || s.GitHubAppPrivateKey != ""
+}
+
+func newAppTokenSource(ctx context.Context, source Source) (*appTokenSource, error) {
+	if source.AccessToken != "" {
+		return nil, errors.New("access_token and github_app_* credentials are mutually exclusive")
+	}
+
+	if source.GitHubAppID == 0 || source.GitHubAppInstallationID == 0 || source.GitHubAppPrivateKey == "" {
+		return nil, errors.New("github_app_id, github_app_installation_id and github_app_private_key must all be set")
+	}
+
+	privateKey, err := parseAppPrivateKey(source.GitHubAppPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := source.GitHubAPIURL
+	if apiURL == "" {
+		apiURL = defaultGitHubAPIURL
+	}
+	if !strings.HasSuffix(apiURL, "/") {
+		apiURL += "/"
+	}
+
+	return &appTokenSource{
+		ctx:            ctx,
+		appID:          source.GitHubAppID,
+		installationID: source.GitHubAppInstallationID,
+		privateKey:     privateKey,
+		tokenURL:       fmt.Sprintf("%sapp/installations/%d/access_tokens", apiURL, source.GitHubAppInstallationID),
+		now:            time.Now,
+	}, nil
+}
+
+func parseAppPrivateKey(key string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("github_app_private_key is not a PEM encoded key")
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse github_app_private_key: %w", err)
+	}
+
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github_app_private_key must be an RSA key")
+	}
+
+	return privateKey, nil
+}
+
+// Token exchanges a freshly signed JWT for an installation token. It is
+// wrapped in an oauth2.ReuseTokenSource so this only happens once the
+// previous token is about to expire.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.signJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, "POST", s.tokenURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	httpClient := http.DefaultClient
+	if c, ok := s.ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		httpClient = c
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("could not create installation token for app %d: HTTP status %d", s.appID, resp.StatusCode)
+	}
+
+	var installationToken struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&installationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{
+		AccessToken: installationToken.Token,
+		TokenType:   "Bearer",
+		Expiry:      installationToken.ExpiresAt,
+	}, nil
+}
+
+func (s *appTokenSource) signJWT() (string, error) {
+	now := s.now()
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Backdate the issue time to allow for clock drift; GitHub rejects
+	// tokens that expire more than 10 minutes in the future.
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
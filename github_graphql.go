@@ -8,11 +8,11 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 	"github.com/shurcooL/githubv4"
 )
 
-func (g *GitHubClient) listReleasesV4EnterPrice() ([]*github.RepositoryRelease, error) {
+func (g *GitHubClient) listReleasesV4EnterPrice(ctx context.Context) ([]*github.RepositoryRelease, error) {
 	if g.clientV4 == nil {
 		return nil, errors.New("github graphql is not been initialised")
 	}
@@ -41,7 +41,7 @@ func (g *GitHubClient) listReleasesV4EnterPrice() ([]*github.RepositoryRelease,
 
 	var allReleases []*github.RepositoryRelease
 	for {
-		if err := g.clientV4.Query(context.TODO(), &listReleasesEnterprise, vars); err != nil {
+		if err := g.clientV4.Query(idempotentContext(ctx), &listReleasesEnterprise, vars); err != nil {
 			return nil, err
 		}
 		for _, r := range listReleasesEnterprise.Repository.Releases.Edges {
@@ -63,6 +63,10 @@ func (g *GitHubClient) listReleasesV4EnterPrice() ([]*github.RepositoryRelease,
 				return nil, err
 			}
 
+			if r.Node.TagCommit != nil {
+				g.cacheTagCommit(r.Node.TagName, r.Node.TagCommit.OID)
+			}
+
 			allReleases = append(allReleases, &github.RepositoryRelease{
 				ID:          &releaseID,
 				TagName:     &r.Node.TagName,
@@ -72,6 +76,8 @@ func (g *GitHubClient) listReleasesV4EnterPrice() ([]*github.RepositoryRelease,
 				URL:         &r.Node.URL,
 				PublishedAt: &github.Timestamp{Time: publishedAt},
 				CreatedAt:   &github.Timestamp{Time: createdAt},
+				Body:        r.Node.Description,
+				Author:      r.Node.Author.author(),
 			})
 		}
 		if !listReleasesEnterprise.Repository.Releases.PageInfo.HasNextPage {
@@ -83,7 +89,7 @@ func (g *GitHubClient) listReleasesV4EnterPrice() ([]*github.RepositoryRelease,
 	return allReleases, nil
 }
 
-func (g *GitHubClient) listReleasesV4() ([]*github.RepositoryRelease, error) {
+func (g *GitHubClient) listReleasesV4(ctx context.Context) ([]*github.RepositoryRelease, error) {
 	if g.clientV4 == nil {
 		return nil, errors.New("github graphql is not been initialised")
 	}
@@ -112,7 +118,7 @@ func (g *GitHubClient) listReleasesV4() ([]*github.RepositoryRelease, error) {
 
 	var allReleases []*github.RepositoryRelease
 	for {
-		if err := g.clientV4.Query(context.TODO(), &listReleases, vars); err != nil {
+		if err := g.clientV4.Query(idempotentContext(ctx), &listReleases, vars); err != nil {
 			return nil, err
 		}
 
@@ -139,6 +145,10 @@ func (g *GitHubClient) listReleasesV4() ([]*github.RepositoryRelease, error) {
 				releaseID = int64(r.Node.DatabaseId)
 			}
 
+			if r.Node.TagCommit != nil {
+				g.cacheTagCommit(r.Node.TagName, r.Node.TagCommit.OID)
+			}
+
 			allReleases = append(allReleases, &github.RepositoryRelease{
 				ID:          &releaseID,
 				TagName:     &r.Node.TagName,
@@ -148,6 +158,8 @@ func (g *GitHubClient) listReleasesV4() ([]*github.RepositoryRelease, error) {
 				URL:         &r.Node.URL,
 				PublishedAt: &github.Timestamp{Time: publishedAt},
 				CreatedAt:   &github.Timestamp{Time: createdAt},
+				Body:        r.Node.Description,
+				Author:      r.Node.Author.author(),
 			})
 		}
 
@@ -1,10 +1,25 @@
 package resource_test
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/concourse/github-release-resource"
@@ -12,7 +27,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 	"github.com/onsi/gomega/ghttp"
 )
 
@@ -179,7 +194,7 @@ var _ = Describe("GitHub Client", func() {
 				client, err = NewGitHubClient(source)
 				Ω(err).ShouldNot(HaveOccurred())
 
-				_, err := client.ListReleases()
+				_, err := client.ListReleases(context.Background())
 				Ω(err).ShouldNot(HaveOccurred())
 			})
 			It("should always append graphql", func() {
@@ -196,7 +211,7 @@ var _ = Describe("GitHub Client", func() {
 				client, err = NewGitHubClient(source)
 				Ω(err).ShouldNot(HaveOccurred())
 
-				_, err := client.ListReleases()
+				_, err := client.ListReleases(context.Background())
 				Ω(err).ShouldNot(HaveOccurred())
 			})
 		})
@@ -221,7 +236,7 @@ var _ = Describe("GitHub Client", func() {
 		})
 
 		It("sends one", func() {
-			_, err := client.ListReleases()
+			_, err := client.ListReleases(context.Background())
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 	})
@@ -243,11 +258,461 @@ var _ = Describe("GitHub Client", func() {
 		})
 
 		It("sends one", func() {
-			_, err := client.ListReleases()
+			_, err := client.ListReleases(context.Background())
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 	})
 
+	Context("with GitHub App credentials", func() {
+		var privateKey *rsa.PrivateKey
+
+		BeforeEach(func() {
+			var err error
+			privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			source = Source{
+				Owner:                   "concourse",
+				Repository:              "concourse",
+				GitHubAppID:             1234,
+				GitHubAppInstallationID: 5678,
+				GitHubAppPrivateKey: string(pem.EncodeToMemory(&pem.Block{
+					Type:  "RSA PRIVATE KEY",
+					Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+				})),
+			}
+		})
+
+		verifyJWT := func(w http.ResponseWriter, r *http.Request) {
+			jwt, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			Ω(found).Should(BeTrue())
+
+			parts := strings.Split(jwt, ".")
+			Ω(parts).Should(HaveLen(3))
+
+			signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+			Ω(err).ShouldNot(HaveOccurred())
+
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			Ω(rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature)).Should(Succeed())
+
+			claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(claims).Should(ContainSubstring(`"iss":"1234"`))
+		}
+
+		It("exchanges a signed JWT for an installation token", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/app/installations/5678/access_tokens"),
+					verifyJWT,
+					ghttp.RespondWith(201, `{"token":"ghs_installation","expires_at":"`+time.Now().Add(time.Hour).Format(time.RFC3339)+`"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/graphql"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer ghs_installation"),
+					ghttp.RespondWith(200, singlePageRespEnterprise),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer ghs_installation"),
+					ghttp.RespondWith(200, `{"id":1}`),
+				),
+			)
+
+			_, err := client.ListReleases(context.Background())
+			Ω(err).ShouldNot(HaveOccurred())
+
+			_, err = client.GetRelease(context.Background(), 1)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(server.ReceivedRequests()).Should(HaveLen(3))
+		})
+
+		It("refreshes the installation token once it expires", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/app/installations/5678/access_tokens"),
+					ghttp.RespondWith(201, `{"token":"ghs_expired","expires_at":"`+time.Now().Add(-time.Minute).Format(time.RFC3339)+`"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer ghs_expired"),
+					ghttp.RespondWith(200, `{"id":1}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/app/installations/5678/access_tokens"),
+					ghttp.RespondWith(201, `{"token":"ghs_refreshed","expires_at":"`+time.Now().Add(time.Hour).Format(time.RFC3339)+`"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer ghs_refreshed"),
+					ghttp.RespondWith(200, `{"id":1}`),
+				),
+			)
+
+			_, err := client.GetRelease(context.Background(), 1)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			_, err = client.GetRelease(context.Background(), 1)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("returns an error if the installation token cannot be created", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/app/installations/5678/access_tokens"),
+					ghttp.RespondWith(401, `{"message":"Bad credentials"}`),
+				),
+			)
+
+			_, err := client.GetRelease(context.Background(), 1)
+			Ω(err).Should(MatchError(ContainSubstring("could not create installation token for app 1234")))
+		})
+
+		It("rejects incomplete credentials", func() {
+			source.GitHubAppPrivateKey = ""
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError("github_app_id, github_app_installation_id and github_app_private_key must all be set"))
+		})
+
+		It("rejects being combined with an access token", func() {
+			source.AccessToken = "abc123"
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError("access_token and github_app_* credentials are mutually exclusive"))
+		})
+	})
+
+	Context("with TLS settings", func() {
+		var caCerts string
+
+		BeforeEach(func() {
+			server.Close()
+			server = ghttp.NewTLSServer()
+
+			caCerts = string(pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: server.HTTPTestServer.Certificate().Raw,
+			}))
+
+			source = Source{
+				Owner:      "concourse",
+				Repository: "concourse",
+			}
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/tags/v1"),
+					ghttp.RespondWith(200, `{"id":1}`),
+				),
+			)
+		})
+
+		It("fails to verify a server with a private CA by default", func() {
+			_, err := client.GetReleaseByTag(context.Background(), "v1")
+			Ω(err).Should(MatchError(ContainSubstring("certificate")))
+		})
+
+		Context("when ca_certs includes the server's CA", func() {
+			BeforeEach(func() {
+				source.CACerts = caCerts
+			})
+
+			It("verifies the server", func() {
+				release, err := client.GetReleaseByTag(context.Background(), "v1")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(release.GetID()).Should(Equal(int64(1)))
+			})
+
+			It("verifies the storage an asset is redirected to", func() {
+				server.SetHandler(0, ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/assets/42"),
+					ghttp.RespondWith(302, "", http.Header{"Location": {server.URL() + "/asset"}}),
+				))
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/asset"),
+						ghttp.RespondWith(200, "some-asset"),
+					),
+				)
+
+				readCloser, err := client.DownloadReleaseAsset(context.Background(), github.ReleaseAsset{ID: github.Int64(42)}, 0)
+				Ω(err).ShouldNot(HaveOccurred())
+				defer readCloser.Close()
+
+				Ω(io.ReadAll(readCloser)).Should(Equal([]byte("some-asset")))
+			})
+		})
+
+		Context("when the server requires a client certificate", func() {
+			var clientCert, clientKey string
+
+			BeforeEach(func() {
+				var clientCA *x509.Certificate
+				clientCA, clientCert, clientKey = generateClientCertificate()
+
+				pool := x509.NewCertPool()
+				pool.AddCert(clientCA)
+
+				server.Close()
+				server = ghttp.NewUnstartedServer()
+				server.HTTPTestServer.TLS = &tls.Config{
+					ClientAuth: tls.RequireAndVerifyClientCert,
+					ClientCAs:  pool,
+				}
+				server.HTTPTestServer.StartTLS()
+
+				source.CACerts = string(pem.EncodeToMemory(&pem.Block{
+					Type:  "CERTIFICATE",
+					Bytes: server.HTTPTestServer.Certificate().Raw,
+				}))
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/tags/v1"),
+						ghttp.RespondWith(200, `{"id":1}`),
+					),
+				)
+			})
+
+			It("presents client_cert and client_key", func() {
+				source.ClientCert = clientCert
+				source.ClientKey = clientKey
+				client, err := NewGitHubClient(source)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				_, err = client.GetReleaseByTag(context.Background(), "v1")
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+
+			It("is rejected without a client certificate", func() {
+				_, err := client.GetReleaseByTag(context.Background(), "v1")
+				Ω(err).Should(HaveOccurred())
+			})
+
+			It("rejects a client_cert without a client_key", func() {
+				source.ClientCert = clientCert
+
+				_, err := NewGitHubClient(source)
+				Ω(err).Should(MatchError("client_cert and client_key must be set together"))
+			})
+
+			It("rejects a client_key that does not match the client_cert", func() {
+				_, _, otherKey := generateClientCertificate()
+				source.ClientCert = clientCert
+				source.ClientKey = otherKey
+
+				_, err := NewGitHubClient(source)
+				Ω(err).Should(MatchError(ContainSubstring("invalid client certificate")))
+			})
+		})
+
+		It("rejects ca_certs without any certificates", func() {
+			source.CACerts = "not a certificate"
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError("invalid ca_certs: no PEM encoded certificates found"))
+		})
+	})
+
+	Context("with a proxy", func() {
+		var proxy *ghttp.Server
+
+		BeforeEach(func() {
+			proxy = ghttp.NewServer()
+
+			source = Source{
+				Owner:      "concourse",
+				Repository: "concourse",
+				ProxyURL:   proxy.URL(),
+			}
+		})
+
+		JustBeforeEach(func() {
+			// The proxy stands in for the unreachable GitHub host.
+			source.GitHubAPIURL = "http://github.example.invalid/"
+
+			var err error
+			client, err = NewGitHubClient(source)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			proxy.Close()
+		})
+
+		It("sends requests through proxy_url", func() {
+			proxy.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/tags/v1"),
+					ghttp.VerifyHeader(http.Header{"Proxy-Authorization": nil}),
+					func(w http.ResponseWriter, r *http.Request) {
+						Ω(r.Host).Should(Equal("github.example.invalid"))
+					},
+					ghttp.RespondWith(200, `{"id":1}`),
+				),
+			)
+
+			release, err := client.GetReleaseByTag(context.Background(), "v1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(release.GetID()).Should(Equal(int64(1)))
+		})
+
+		It("sends redirected asset downloads through proxy_url", func() {
+			proxy.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/assets/42"),
+					ghttp.RespondWith(302, "", http.Header{"Location": {"http://storage.example.invalid/asset"}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/asset"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Ω(r.Host).Should(Equal("storage.example.invalid"))
+					},
+					ghttp.RespondWith(200, "some-asset"),
+				),
+			)
+
+			readCloser, err := client.DownloadReleaseAsset(context.Background(), github.ReleaseAsset{ID: github.Int64(42)}, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			defer readCloser.Close()
+
+			Ω(io.ReadAll(readCloser)).Should(Equal([]byte("some-asset")))
+		})
+
+		Context("with proxy credentials", func() {
+			BeforeEach(func() {
+				source.ProxyUsername = "user"
+				source.ProxyPassword = "secret"
+			})
+
+			It("authenticates with the proxy", func() {
+				proxy.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/tags/v1"),
+						ghttp.VerifyHeaderKV("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret"))),
+						ghttp.RespondWith(200, `{"id":1}`),
+					),
+				)
+
+				_, err := client.GetReleaseByTag(context.Background(), "v1")
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("when the host matches no_proxy", func() {
+			BeforeEach(func() {
+				source.NoProxy = []string{"other.example.com", ".example.invalid"}
+			})
+
+			It("connects directly", func() {
+				_, err := client.GetReleaseByTag(context.Background(), "v1")
+				Ω(err).Should(HaveOccurred())
+				Ω(proxy.ReceivedRequests()).Should(BeEmpty())
+			})
+		})
+
+		It("rejects an invalid proxy_url", func() {
+			source.ProxyURL = "ftp://proxy.example.com"
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError(ContainSubstring("invalid proxy_url")))
+		})
+
+		It("rejects proxy settings without proxy_url", func() {
+			source.ProxyURL = ""
+			source.NoProxy = []string{"example.com"}
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError("proxy_username, proxy_password and no_proxy require proxy_url"))
+		})
+	})
+
+	Context("with timeouts", func() {
+		BeforeEach(func() {
+			source = Source{
+				Owner:          "concourse",
+				Repository:     "concourse",
+				AccessToken:    "abc123",
+				RequestTimeout: "50ms",
+			}
+		})
+
+		slowly := func(handler http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(200 * time.Millisecond):
+				case <-r.Context().Done():
+				}
+				handler(w, r)
+			}
+		}
+
+		It("gives up on a request after request_timeout", func() {
+			server.AppendHandlers(slowly(ghttp.RespondWith(200, `{"id":1}`)))
+
+			_, err := client.GetReleaseByTag(context.Background(), "v1")
+			Ω(err).Should(MatchError(ContainSubstring("timed out after 50ms")))
+		})
+
+		It("does not bound asset downloads by request_timeout", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/assets/42"),
+					slowly(ghttp.RespondWith(200, "some-asset")),
+				),
+			)
+
+			readCloser, err := client.DownloadReleaseAsset(context.Background(), github.ReleaseAsset{ID: github.Int64(42)}, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			defer readCloser.Close()
+
+			Ω(io.ReadAll(readCloser)).Should(Equal([]byte("some-asset")))
+		})
+
+		Context("with a transfer_timeout", func() {
+			BeforeEach(func() {
+				source.TransferTimeout = "50ms"
+			})
+
+			It("gives up on an asset download after transfer_timeout", func() {
+				server.AppendHandlers(slowly(ghttp.RespondWith(200, "some-asset")))
+
+				_, err := client.DownloadReleaseAsset(context.Background(), github.ReleaseAsset{ID: github.Int64(42)}, 0)
+				Ω(err).Should(MatchError(ContainSubstring("timed out after 50ms")))
+			})
+		})
+
+		It("stops when the context is cancelled", func() {
+			server.AppendHandlers(slowly(ghttp.RespondWith(200, `{"id":1}`)))
+			source.RequestTimeout = ""
+			client, err := NewGitHubClient(source)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			_, err = client.GetReleaseByTag(ctx, "v1")
+			Ω(err).Should(MatchError(context.Canceled))
+		})
+
+		It("rejects an invalid connect_timeout", func() {
+			source.ConnectTimeout = "soon"
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError(ContainSubstring("invalid connect_timeout")))
+		})
+
+		It("rejects a negative request_timeout", func() {
+			source.RequestTimeout = "-1s"
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError("invalid request_timeout: must not be negative"))
+		})
+	})
+
 	Describe("ListReleases with access token", func() {
 		BeforeEach(func() {
 			source = Source{
@@ -271,7 +736,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("list releases", func() {
-				releases, err := client.ListReleases()
+				releases, err := client.ListReleases(context.Background())
 				Ω(err).ShouldNot(HaveOccurred())
 				Expect(releases).To(HaveLen(3))
 				Expect(server.ReceivedRequests()).To(HaveLen(2))
@@ -310,6 +775,68 @@ var _ = Describe("GitHub Client", func() {
 			})
 		})
 
+		Context("List graphql releases with details", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/graphql"),
+						ghttp.VerifyBody([]byte(`{"query":"query($releaseCursor:String$releasesCount:Int!$repositoryName:String!$repositoryOwner:String!){repository(owner:$repositoryOwner,name:$repositoryName){releases(first:$releasesCount, after: $releaseCursor, orderBy: {field: CREATED_AT, direction: DESC}){edges{node{createdAt,publishedAt,id,isDraft,isPrerelease,name,tagName,url,description,author{login},tagCommit{oid}}},pageInfo{endCursor,hasNextPage}}}}","variables":{"releaseCursor":null,"releasesCount":100,"repositoryName":"concourse","repositoryOwner":"concourse"}}`+"\n")),
+						ghttp.RespondWith(200, `{
+  "data": {
+    "repository": {
+      "releases": {
+        "edges": [
+          {
+            "node": {
+              "createdAt": "2010-10-10T01:01:07Z",
+              "id": "MDc6UmVsZWFzZTMzMjIyMjQz",
+              "name": "xyq",
+              "publishedAt": "2010-10-10T15:39:53Z",
+              "tagName": "xyq",
+              "url": "https://github.com/xyq/xyq/releases/tag/xyq",
+              "isDraft": false,
+              "isPrerelease": false,
+              "description": "*markdown*",
+              "author": {
+                "login": "octocat"
+              },
+              "tagCommit": {
+                "oid": "f28085a4a8f744da83411f5e09fd7b1709149eee"
+              }
+            }
+          }
+        ],
+        "pageInfo": {
+          "endCursor": "Y3Vyc29yOnYyOpK5MjAyMC0xMC0wMVQwMjo1ODowNyswMjowMM4B6bt_",
+          "hasNextPage": false
+        }
+      }
+    }
+  }
+}`),
+					),
+				)
+			})
+
+			It("lists the body and author along with the releases", func() {
+				releases, err := client.ListReleases(context.Background())
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(releases).Should(HaveLen(1))
+				Ω(releases[0].GetBody()).Should(Equal("*markdown*"))
+				Ω(releases[0].GetAuthor().GetLogin()).Should(Equal("octocat"))
+			})
+
+			It("resolves the tags of the releases without further requests", func() {
+				_, err := client.ListReleases(context.Background())
+				Ω(err).ShouldNot(HaveOccurred())
+
+				sha, err := client.ResolveTagToCommitSHA(context.Background(), "xyq")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(sha).Should(Equal("f28085a4a8f744da83411f5e09fd7b1709149eee"))
+				Ω(server.ReceivedRequests()).Should(HaveLen(1))
+			})
+		})
+
 		Context("List graphql releases with bad id", func() {
 			BeforeEach(func() {
 				server.SetAllowUnhandledRequests(true)
@@ -320,7 +847,7 @@ var _ = Describe("GitHub Client", func() {
 					))
 			})
 			It("list releases with incorrect id", func() {
-				_, err := client.ListReleases()
+				_, err := client.ListReleases(context.Background())
 				Ω(err).Should(HaveOccurred())
 			})
 		})
@@ -349,7 +876,7 @@ var _ = Describe("GitHub Client", func() {
 					)
 				})
 				It("list releases", func() {
-					releases, err := client.ListReleases()
+					releases, err := client.ListReleases(context.Background())
 					Ω(err).ShouldNot(HaveOccurred())
 					Expect(releases).To(HaveLen(101))
 					Expect(server.ReceivedRequests()).To(HaveLen(2))
@@ -380,7 +907,7 @@ var _ = Describe("GitHub Client", func() {
 				)
 			})
 			It("lists all release assets", func() {
-				releasesAssets, err := client.ListReleaseAssets(github.RepositoryRelease{ID: github.Int64(1)})
+				releasesAssets, err := client.ListReleaseAssets(context.Background(), github.RepositoryRelease{ID: github.Int64(1)})
 				Ω(err).ShouldNot(HaveOccurred())
 				Expect(releasesAssets).To(HaveLen(50))
 				Expect(server.ReceivedRequests()).To(HaveLen(1))
@@ -402,7 +929,7 @@ var _ = Describe("GitHub Client", func() {
 					)
 				})
 				It("list release assets", func() {
-					releasesAssets, err := client.ListReleaseAssets(github.RepositoryRelease{ID: github.Int64(1)})
+					releasesAssets, err := client.ListReleaseAssets(context.Background(), github.RepositoryRelease{ID: github.Int64(1)})
 					Ω(err).ShouldNot(HaveOccurred())
 					Expect(releasesAssets).To(HaveLen(102))
 					Expect(server.ReceivedRequests()).To(HaveLen(2))
@@ -438,13 +965,74 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("Returns an appropriate error", func() {
-				_, err := client.GetRelease(20)
+				_, err := client.GetRelease(context.Background(), 20)
 				Expect(err).ToNot(BeNil())
 				Expect(err.Error()).To(ContainSubstring("API rate limit exceeded for 127.0.0.1. (But here's the good news: Authenticated requests get a higher rate limit. Check out the documentation for more details.)"))
 			})
 		})
 	})
 
+	Describe("GetLatestRelease", func() {
+		BeforeEach(func() {
+			source = Source{
+				Owner:      "concourse",
+				Repository: "concourse",
+			}
+		})
+
+		Context("When GitHub responds successfully", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/latest"),
+						ghttp.RespondWith(200, `{ "id": 1 }`),
+					),
+				)
+			})
+
+			It("Returns the latest release", func() {
+				release, err := client.GetLatestRelease(context.Background())
+
+				Ω(err).ShouldNot(HaveOccurred())
+				Expect(release).To(Equal(&github.RepositoryRelease{ID: github.Int64(1)}))
+			})
+		})
+
+		Context("When there is no latest release", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/latest"),
+						ghttp.RespondWith(404, `{ "message": "Not Found" }`),
+					),
+				)
+			})
+
+			It("Returns no release", func() {
+				release, err := client.GetLatestRelease(context.Background())
+
+				Ω(err).ShouldNot(HaveOccurred())
+				Expect(release).To(BeNil())
+			})
+		})
+
+		Context("When GitHub fails", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/latest"),
+						ghttp.RespondWith(401, `{ "message": "Bad credentials" }`),
+					),
+				)
+			})
+
+			It("Returns an error", func() {
+				_, err := client.GetLatestRelease(context.Background())
+				Expect(err).To(MatchError(ContainSubstring("Bad credentials")))
+			})
+		})
+	})
+
 	Describe("GetReleaseByTag", func() {
 		BeforeEach(func() {
 			source = Source{
@@ -472,7 +1060,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("Returns an appropriate error", func() {
-				_, err := client.GetReleaseByTag("some-tag")
+				_, err := client.GetReleaseByTag(context.Background(), "some-tag")
 				Expect(err).ToNot(BeNil())
 				Expect(err.Error()).To(ContainSubstring("API rate limit exceeded for 127.0.0.1. (But here's the good news: Authenticated requests get a higher rate limit. Check out the documentation for more details.)"))
 			})
@@ -493,7 +1081,7 @@ var _ = Describe("GitHub Client", func() {
 					ID: github.Int64(1),
 				}
 
-				release, err := client.GetReleaseByTag("some-tag")
+				release, err := client.GetReleaseByTag(context.Background(), "some-tag")
 
 				Ω(err).ShouldNot(HaveOccurred())
 				Expect(release).To(Equal(expectedRelease))
@@ -501,6 +1089,44 @@ var _ = Describe("GitHub Client", func() {
 		})
 	})
 
+	Describe("IsCommitReachable", func() {
+		BeforeEach(func() {
+			source = Source{
+				Owner:      "concourse",
+				Repository: "concourse",
+			}
+		})
+
+		DescribeTable("compares the commit with the branch",
+			func(status string, reachable bool) {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/repos/concourse/concourse/compare/release/1.x...some-sha"),
+						ghttp.RespondWith(200, `{"status": "`+status+`"}`),
+					),
+				)
+
+				Ω(client.IsCommitReachable(context.Background(), "some-sha", "release/1.x")).Should(Equal(reachable))
+			},
+			Entry("at the head of the branch", "identical", true),
+			Entry("in the history of the branch", "behind", true),
+			Entry("ahead of the branch", "ahead", false),
+			Entry("on another branch", "diverged", false),
+		)
+
+		It("fails if the branch does not exist", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/compare/nope...some-sha"),
+					ghttp.RespondWith(404, `{"message": "Not Found"}`),
+				),
+			)
+
+			_, err := client.IsCommitReachable(context.Background(), "some-sha", "nope")
+			Ω(err).Should(MatchError(ContainSubstring("could not compare commit some-sha with branch 'nope'")))
+		})
+	})
+
 	Describe("ResolveTagToCommitSHA", func() {
 		BeforeEach(func() {
 			source = Source{
@@ -528,7 +1154,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("Returns an appropriate error", func() {
-				_, err := client.ResolveTagToCommitSHA("some-tag")
+				_, err := client.ResolveTagToCommitSHA(context.Background(), "some-tag")
 				Expect(err).ToNot(BeNil())
 				Expect(err.Error()).To(ContainSubstring("API rate limit exceeded for 127.0.0.1. (But here's the good news: Authenticated requests get a higher rate limit. Check out the documentation for more details.)"))
 			})
@@ -545,7 +1171,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("Returns the associated commit SHA", func() {
-				reference, err := client.ResolveTagToCommitSHA("some-tag")
+				reference, err := client.ResolveTagToCommitSHA(context.Background(), "some-tag")
 
 				Ω(err).ShouldNot(HaveOccurred())
 				Expect(reference).To(Equal("some-sha"))
@@ -573,7 +1199,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("Returns the associated commit SHA", func() {
-					reference, err := client.ResolveTagToCommitSHA("some-tag")
+					reference, err := client.ResolveTagToCommitSHA(context.Background(), "some-tag")
 
 					Ω(err).ShouldNot(HaveOccurred())
 					Expect(reference).To(Equal("some-sha"))
@@ -591,7 +1217,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("Returns an error", func() {
-					_, err := client.ResolveTagToCommitSHA("some-tag")
+					_, err := client.ResolveTagToCommitSHA(context.Background(), "some-tag")
 					Ω(err).Should(HaveOccurred())
 				})
 			})
@@ -625,7 +1251,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("follows the chain and returns the final commit SHA", func() {
-				commitSHA, err := client.ResolveTagToCommitSHA("v1.0.0-rc90")
+				commitSHA, err := client.ResolveTagToCommitSHA(context.Background(), "v1.0.0-rc90")
 
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(commitSHA).To(Equal("final-commit-sha"))
@@ -656,7 +1282,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("returns an error when max depth is exceeded", func() {
-				_, err := client.ResolveTagToCommitSHA("deeply-nested")
+				_, err := client.ResolveTagToCommitSHA(context.Background(), "deeply-nested")
 
 				Expect(len(server.ReceivedRequests())).To(Equal(11)) // first call + max depth value
 				Expect(err).Should(HaveOccurred())
@@ -682,7 +1308,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("returns an error for unexpected object types", func() {
-				_, err := client.ResolveTagToCommitSHA("bad-tag")
+				_, err := client.ResolveTagToCommitSHA(context.Background(), "bad-tag")
 
 				Expect(err).Should(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("expected 'commit' or 'tag'"))
@@ -741,7 +1367,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns the correct body", func() {
-					readCloser, err := client.DownloadReleaseAsset(asset)
+					readCloser, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).NotTo(HaveOccurred())
 					defer readCloser.Close()
 
@@ -757,12 +1383,61 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns an error", func() {
-					_, err := client.DownloadReleaseAsset(asset)
+					_, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).To(HaveOccurred())
 				})
 			})
 		})
 
+		Context("when resuming at an offset", func() {
+			var rangeHandler = func(path string, statusCode int, body string, headers ...http.Header) http.HandlerFunc {
+				return ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", path),
+					ghttp.VerifyHeaderKV("Range", "bytes=5-"),
+					ghttp.RespondWith(statusCode, body, headers...),
+				)
+			}
+
+			var readAll = func() (string, error) {
+				readCloser, err := client.DownloadReleaseAsset(context.Background(), asset, 5)
+				if err != nil {
+					return "", err
+				}
+				defer readCloser.Close()
+
+				body, err := io.ReadAll(readCloser)
+				return string(body), err
+			}
+
+			It("requests the rest of the asset", func() {
+				server.AppendHandlers(rangeHandler(assetPath, 206, "56789", http.Header{"Content-Range": {"bytes 5-9/10"}}))
+
+				Ω(readAll()).Should(Equal("56789"))
+			})
+
+			It("skips the start of the asset if the server ignores the range", func() {
+				server.AppendHandlers(rangeHandler(assetPath, 200, "0123456789"))
+
+				Ω(readAll()).Should(Equal("56789"))
+			})
+
+			It("requests the rest of the asset from the redirect", func() {
+				server.AppendHandlers(
+					rangeHandler(assetPath, 302, "", locationHeader("/the/redirect/path")),
+					rangeHandler("/the/redirect/path", 206, "56789", http.Header{"Content-Range": {"bytes 5-9/10"}}),
+				)
+
+				Ω(readAll()).Should(Equal("56789"))
+			})
+
+			It("fails if the server responds with a different range", func() {
+				server.AppendHandlers(rangeHandler(assetPath, 206, "0123456789", http.Header{"Content-Range": {"bytes 0-9/10"}}))
+
+				_, err := readAll()
+				Ω(err).Should(MatchError(`unexpected Content-Range "bytes 0-9/10" when resuming at byte 5`))
+			})
+		})
+
 		Context("when the asset is behind a redirect", func() {
 			const redirectPath = "/the/redirect/path"
 
@@ -780,7 +1455,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns the body from the redirect request", func() {
-					readCloser, err := client.DownloadReleaseAsset(asset)
+					readCloser, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).NotTo(HaveOccurred())
 					defer readCloser.Close()
 
@@ -802,7 +1477,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns the body from the final redirect request", func() {
-					readCloser, err := client.DownloadReleaseAsset(asset)
+					readCloser, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).NotTo(HaveOccurred())
 					defer readCloser.Close()
 
@@ -830,7 +1505,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("downloads the file without the Authorization header", func() {
-					readCloser, err := client.DownloadReleaseAsset(asset)
+					readCloser, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).NotTo(HaveOccurred())
 					defer readCloser.Close()
 
@@ -846,7 +1521,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns an error", func() {
-					_, err := client.DownloadReleaseAsset(asset)
+					_, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).To(HaveOccurred())
 				})
 			})
@@ -857,7 +1532,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns an error", func() {
-					_, err := client.DownloadReleaseAsset(asset)
+					_, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).To(HaveOccurred())
 				})
 			})
@@ -869,7 +1544,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns an error", func() {
-					_, err := client.DownloadReleaseAsset(asset)
+					_, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).To(HaveOccurred())
 				})
 			})
@@ -880,7 +1555,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns an error", func() {
-					_, err := client.DownloadReleaseAsset(asset)
+					_, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).To(HaveOccurred())
 				})
 			})
@@ -891,7 +1566,7 @@ var _ = Describe("GitHub Client", func() {
 				})
 
 				It("returns an error", func() {
-					_, err := client.DownloadReleaseAsset(asset)
+					_, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 					Expect(err).To(HaveOccurred())
 				})
 			})
@@ -912,7 +1587,7 @@ var _ = Describe("GitHub Client", func() {
 			})
 
 			It("downloads the file without the Authorization header", func() {
-				readCloser, err := client.DownloadReleaseAsset(asset)
+				readCloser, err := client.DownloadReleaseAsset(context.Background(), asset, 0)
 				Expect(err).NotTo(HaveOccurred())
 				defer readCloser.Close()
 
@@ -922,4 +1597,245 @@ var _ = Describe("GitHub Client", func() {
 			})
 		})
 	})
+
+	Describe("UpdateReleaseAsset", func() {
+		BeforeEach(func() {
+			source = Source{
+				Owner:       "concourse",
+				Repository:  "concourse",
+				AccessToken: "abc123",
+			}
+		})
+
+		It("only sends the name and label of the asset", func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PATCH", "/repos/concourse/concourse/releases/assets/42"),
+				ghttp.VerifyJSON(`{"name":"final.tgz"}`),
+				ghttp.RespondWith(200, `{"id":42,"name":"final.tgz"}`),
+			))
+
+			asset, err := client.UpdateReleaseAsset(context.Background(), github.ReleaseAsset{
+				ID:    github.Int64(42),
+				Name:  github.String("final.tgz"),
+				State: github.String("uploaded"),
+				Size:  github.Int(3),
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*asset.Name).Should(Equal("final.tgz"))
+		})
+
+		It("requires the asset to have an ID", func() {
+			_, err := client.UpdateReleaseAsset(context.Background(), github.ReleaseAsset{Name: github.String("final.tgz")})
+			Ω(err).Should(MatchError("asset did not have an ID: has it been uploaded yet?"))
+		})
+	})
+
+	Describe("retrying requests", func() {
+		BeforeEach(func() {
+			source = Source{
+				Owner:      "concourse",
+				Repository: "concourse",
+				MaxRetries: 2,
+				MaxWait:    "10ms",
+			}
+		})
+
+		It("retries idempotent requests that fail with a server error", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.RespondWith(502, "bad gateway"),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.RespondWith(200, `{"id":1}`),
+				),
+			)
+
+			release, err := client.GetRelease(context.Background(), 1)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*release.ID).Should(Equal(int64(1)))
+			Ω(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+
+		It("gives up after max_retries", func() {
+			for range 3 {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+						ghttp.RespondWith(503, "unavailable"),
+					),
+				)
+			}
+
+			_, err := client.GetRelease(context.Background(), 1)
+			Ω(err).Should(HaveOccurred())
+			Ω(server.ReceivedRequests()).Should(HaveLen(3))
+		})
+
+		It("does not retry non-idempotent requests that fail with a server error", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/repos/concourse/concourse/releases"),
+					ghttp.RespondWith(502, "bad gateway"),
+				),
+			)
+
+			_, err := client.CreateRelease(context.Background(), github.RepositoryRelease{TagName: github.String("v1.0.0")})
+			Ω(err).Should(HaveOccurred())
+			Ω(server.ReceivedRequests()).Should(HaveLen(1))
+		})
+
+		It("waits for Retry-After on secondary rate limits", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/repos/concourse/concourse/releases"),
+					ghttp.RespondWith(403, `{"message":"You have exceeded a secondary rate limit."}`, http.Header{"Retry-After": {"0"}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/repos/concourse/concourse/releases"),
+					ghttp.VerifyJSON(`{"tag_name":"v1.0.0"}`),
+					ghttp.RespondWith(201, `{"id":1}`),
+				),
+			)
+
+			_, err := client.CreateRelease(context.Background(), github.RepositoryRelease{TagName: github.String("v1.0.0")})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+
+		It("waits for the rate limit to reset", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.RespondWith(403, rateLimitMessage, http.Header{
+						"X-RateLimit-Remaining": {"0"},
+						"X-RateLimit-Reset":     {strconv.FormatInt(time.Now().Unix(), 10)},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.RespondWith(200, `{"id":1}`),
+				),
+			)
+
+			_, err := client.GetRelease(context.Background(), 1)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+
+		It("does not wait longer than max_wait", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.RespondWith(429, "slow down", http.Header{"Retry-After": {"3600"}}),
+				),
+			)
+
+			_, err := client.GetRelease(context.Background(), 1)
+			Ω(err).Should(HaveOccurred())
+			Ω(server.ReceivedRequests()).Should(HaveLen(1))
+		})
+
+		It("does not retry forbidden responses that are not rate limits", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/1"),
+					ghttp.RespondWith(403, `{"message":"Resource not accessible by integration"}`),
+				),
+			)
+
+			_, err := client.GetRelease(context.Background(), 1)
+			Ω(err).Should(MatchError(ContainSubstring("Resource not accessible by integration")))
+			Ω(server.ReceivedRequests()).Should(HaveLen(1))
+		})
+
+		It("retries GraphQL queries", func() {
+			source.AccessToken = "abc123"
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/graphql"),
+					ghttp.RespondWith(502, "bad gateway"),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/graphql"),
+					ghttp.RespondWith(200, singlePageRespEnterprise),
+				),
+			)
+
+			client, err := NewGitHubClient(source)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			releases, err := client.ListReleases(context.Background())
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(releases).Should(HaveLen(1))
+		})
+
+		It("retries the redirected asset download", func() {
+			assetServer := ghttp.NewServer()
+			defer assetServer.Close()
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases/assets/42"),
+					ghttp.RespondWith(302, "", http.Header{"Location": {assetServer.URL() + "/asset"}}),
+				),
+			)
+			assetServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/asset"),
+					ghttp.RespondWith(503, "unavailable"),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/asset"),
+					ghttp.RespondWith(200, "some-asset"),
+				),
+			)
+
+			readCloser, err := client.DownloadReleaseAsset(context.Background(), github.ReleaseAsset{ID: github.Int64(42)}, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			defer readCloser.Close()
+
+			Ω(io.ReadAll(readCloser)).Should(Equal([]byte("some-asset")))
+		})
+
+		It("rejects an invalid max_wait", func() {
+			source.MaxWait = "forever"
+
+			_, err := NewGitHubClient(source)
+			Ω(err).Should(MatchError(ContainSubstring("invalid max_wait")))
+		})
+	})
 })
+
+// generateClientCertificate returns a self-signed certificate for client
+// authentication, along with the PEM encoded certificate and key.
+func generateClientCertificate() (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "github-release-resource"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	cert, err := x509.ParseCertificate(der)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	return cert,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
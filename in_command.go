@@ -1,6 +1,9 @@
 package resource
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -8,8 +11,9 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 )
 
 type InCommand struct {
@@ -17,15 +21,24 @@ type InCommand struct {
 	writer io.Writer
 }
 
+// maxDownloadResumes is how many times a download that failed part way is
+// resumed before giving up.
+const maxDownloadResumes = 5
+
 func NewInCommand(github GitHub, writer io.Writer) *InCommand {
 	return &InCommand{
 		github: github,
-		writer: writer,
+		writer: &syncWriter{writer: writer},
 	}
 }
 
-func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
-	err := os.MkdirAll(destDir, 0755)
+func (c *InCommand) Run(ctx context.Context, destDir string, request InRequest) (InResponse, error) {
+	err := validateExtractParams(request.Params.Extract)
+	if err != nil {
+		return InResponse{}, err
+	}
+
+	err = os.MkdirAll(destDir, 0755)
 	if err != nil {
 		return InResponse{}, err
 	}
@@ -41,12 +54,12 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 	}
 
 	var foundRelease *github.RepositoryRelease
-	var commitSHA string
+	var commitSHA, version string
 
 	id, _ := strconv.Atoi(request.Version.ID)
-	foundRelease, err = c.github.GetRelease(id)
+	foundRelease, err = c.github.GetRelease(ctx, id)
 	if err != nil {
-		foundRelease, err = c.github.GetReleaseByTag(request.Version.Tag)
+		foundRelease, err = c.github.GetReleaseByTag(ctx, request.Version.Tag)
 		if err != nil {
 			return InResponse{}, err
 		}
@@ -71,11 +84,11 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 			return InResponse{}, err
 		}
 
-		versionParser, err := newVersionParser(request.Source.TagFilter)
+		versionParser, err := newVersionParser(request.Source)
 		if err != nil {
 			return InResponse{}, err
 		}
-		version := versionParser.parse(*foundRelease.TagName)
+		version = versionParser.canonical(*foundRelease.TagName)
 		versionPath := filepath.Join(destDir, "version")
 		err = os.WriteFile(versionPath, []byte(version), 0644)
 		if err != nil {
@@ -84,7 +97,7 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 
 		if foundRelease.Draft != nil && !*foundRelease.Draft {
 			commitPath := filepath.Join(destDir, "commit_sha")
-			commitSHA, err = c.github.ResolveTagToCommitSHA(*foundRelease.TagName)
+			commitSHA, err = c.github.ResolveTagToCommitSHA(ctx, *foundRelease.TagName)
 			if err != nil {
 				return InResponse{}, err
 			}
@@ -119,66 +132,95 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 		}
 	}
 
-	assets, err := c.github.ListReleaseAssets(*foundRelease)
+	// Looking up the latest release costs a request, so it is only done
+	// when asked for. Drafts and prereleases are never the latest release.
+	var latest *bool
+	if request.Params.IncludeLatest {
+		latest = github.Ptr(false)
+		if !foundRelease.GetDraft() && !foundRelease.GetPrerelease() {
+			latestRelease, err := c.github.GetLatestRelease(ctx)
+			if err != nil {
+				return InResponse{}, err
+			}
+			*latest = latestRelease != nil && latestRelease.GetID() == foundRelease.GetID()
+		}
+	}
+
+	var components *versionComponents
+	if version != "" {
+		components = parseVersionComponents(version)
+	}
+
+	if components != nil {
+		for name, content := range components.files() {
+			err = os.WriteFile(filepath.Join(destDir, name), []byte(content), 0644)
+			if err != nil {
+				return InResponse{}, err
+			}
+		}
+	}
+
+	metadata := newReleaseMetadata(foundRelease, commitSHA, latest, components)
+	err = writeReleaseMetadata(destDir, metadata, request.Params.IncludeReleaseYAML)
 	if err != nil {
 		return InResponse{}, err
 	}
 
-	for _, asset := range assets {
-		state := asset.State
-		if state == nil || *state != "uploaded" {
-			continue
+	if request.Params.AssetManifest || !request.Params.SkipDownload {
+		assets, err := c.github.ListReleaseAssets(ctx, *foundRelease)
+		if err != nil {
+			return InResponse{}, err
 		}
 
-		path := filepath.Join(assetDir, *asset.Name)
-
-		var matchFound bool
-		if len(request.Params.Globs) == 0 {
-			matchFound = true
+		if request.Params.AssetManifest {
+			err = writeAssetManifest(filepath.Join(destDir, "assets.json"), assets)
 		} else {
-			for _, glob := range request.Params.Globs {
-				matches, err := filepath.Match(glob, *asset.Name)
-				if err != nil {
-					return InResponse{}, err
-				}
-
-				if matches {
-					matchFound = true
-					break
-				}
-			}
+			err = c.fetchAssets(ctx, assets, assetDir, request.Params)
 		}
-
-		if !matchFound {
-			continue
+		if err != nil {
+			return InResponse{}, err
 		}
+	}
 
-		fmt.Fprintf(c.writer, "downloading asset: %s\n", *asset.Name)
+	if request.Params.SkipDownload {
+		return InResponse{
+			Version:  versionFromRelease(foundRelease),
+			Metadata: metadataFromRelease(foundRelease, commitSHA),
+		}, nil
+	}
 
-		err := c.downloadAsset(asset, path)
+	var archiveClient *http.Client
+	if request.Params.IncludeSourceTarball || request.Params.IncludeSourceZip {
+		archiveClient, err = newHTTPClient(request.Source)
 		if err != nil {
 			return InResponse{}, err
 		}
 	}
 
 	if request.Params.IncludeSourceTarball && foundRelease.TagName != nil {
-		u, err := c.github.GetTarballLink(*foundRelease.TagName)
+		u, err := c.github.GetTarballLink(ctx, *foundRelease.TagName)
 		if err != nil {
 			return InResponse{}, err
 		}
 		fmt.Fprintln(c.writer, "downloading source tarball to source.tar.gz")
-		if err := c.downloadFile(u.String(), filepath.Join(assetDir, "source.tar.gz")); err != nil {
+		if err := c.downloadFile(ctx, archiveClient, u.String(), filepath.Join(assetDir, "source.tar.gz")); err != nil {
+			return InResponse{}, err
+		}
+		if err := c.extract(assetDir, "source.tar.gz", request.Params.Extract); err != nil {
 			return InResponse{}, err
 		}
 	}
 
 	if request.Params.IncludeSourceZip && foundRelease.TagName != nil {
-		u, err := c.github.GetZipballLink(*foundRelease.TagName)
+		u, err := c.github.GetZipballLink(ctx, *foundRelease.TagName)
 		if err != nil {
 			return InResponse{}, err
 		}
 		fmt.Fprintln(c.writer, "downloading source zip to source.zip")
-		if err := c.downloadFile(u.String(), filepath.Join(assetDir, "source.zip")); err != nil {
+		if err := c.downloadFile(ctx, archiveClient, u.String(), filepath.Join(assetDir, "source.zip")); err != nil {
+			return InResponse{}, err
+		}
+		if err := c.extract(assetDir, "source.zip", request.Params.Extract); err != nil {
 			return InResponse{}, err
 		}
 	}
@@ -189,35 +231,454 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 	}, nil
 }
 
-func (c *InCommand) downloadAsset(asset *github.ReleaseAsset, destPath string) error {
-	out, err := os.Create(destPath)
+// fetchAssets downloads the uploaded assets matching the globs, verifying
+// and extracting them.
+func (c *InCommand) fetchAssets(ctx context.Context, assets []*github.ReleaseAsset, assetDir string, params InParams) error {
+	var signatures *signatureVerifier
+	if params.Verify.enabled() {
+		var err error
+		signatures, err = newSignatureVerifier(c.github, params.Verify, assets)
+		if err != nil {
+			return err
+		}
+	}
+
+	var sums checksums
+	if params.ChecksumFile != "" {
+		var err error
+		sums, err = c.fetchChecksums(ctx, assets, params.ChecksumFile, signatures)
+		if err != nil {
+			return err
+		}
+	}
+
+	var downloads []*github.ReleaseAsset
+	for _, asset := range assets {
+		state := asset.State
+		if state == nil || *state != "uploaded" {
+			continue
+		}
+
+		var matchFound bool
+		if len(params.Globs) == 0 {
+			matchFound = true
+		} else {
+			for _, glob := range params.Globs {
+				matches, err := filepath.Match(glob, *asset.Name)
+				if err != nil {
+					return err
+				}
+
+				if matches {
+					matchFound = true
+					break
+				}
+			}
+		}
+
+		if !matchFound {
+			continue
+		}
+
+		downloads = append(downloads, asset)
+	}
+
+	err := c.downloadAssets(ctx, downloads, assetDir, sums, signatures, params)
+	if err != nil {
+		return err
+	}
+
+	for _, asset := range downloads {
+		err = c.extract(assetDir, *asset.Name, params.Extract)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assetManifestEntry describes an asset in assets.json and release.json.
+type assetManifestEntry struct {
+	ID          int64  `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Size        int    `json:"size" yaml:"size"`
+	ContentType string `json:"content_type" yaml:"content_type"`
+	DownloadURL string `json:"download_url" yaml:"download_url"`
+	Digest      string `json:"digest,omitempty" yaml:"digest,omitempty"`
+	State       string `json:"state" yaml:"state"`
+}
+
+func assetManifest(assets []*github.ReleaseAsset) []assetManifestEntry {
+	manifest := []assetManifestEntry{}
+	for _, asset := range assets {
+		manifest = append(manifest, assetManifestEntry{
+			ID:          asset.GetID(),
+			Name:        asset.GetName(),
+			Size:        asset.GetSize(),
+			ContentType: asset.GetContentType(),
+			DownloadURL: asset.GetBrowserDownloadURL(),
+			Digest:      asset.GetDigest(),
+			State:       asset.GetState(),
+		})
+	}
+
+	return manifest
+}
+
+// writeAssetManifest describes every asset of the release in the file at
+// path, without downloading any of them.
+func writeAssetManifest(path string, assets []*github.ReleaseAsset) error {
+	content, err := json.MarshalIndent(assetManifest(assets), "", "  ")
 	if err != nil {
 		return err
 	}
+
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// extract extracts the file in dir if it matches the glob of any of the
+// extract params, deleting the archive afterwards if asked to.
+func (c *InCommand) extract(dir, name string, extract []ExtractParams) error {
+	params, found := extractRule(extract, name)
+	if !found {
+		return nil
+	}
+
+	into := filepath.Join(dir, params.Into)
+	fmt.Fprintf(c.writer, "extracting %s into %s\n", name, into)
+
+	archivePath := filepath.Join(dir, name)
+
+	err := extractArchive(archivePath, into, params)
+	if err != nil {
+		return fmt.Errorf("failed to extract '%s': %w", name, err)
+	}
+
+	if params.DeleteArchive {
+		return os.Remove(archivePath)
+	}
+
+	return nil
+}
+
+// downloadAssets downloads and verifies the assets using up to
+// download_concurrency workers. Every asset is attempted, and the errors of
+// all that failed are returned together in the order of the assets.
+func (c *InCommand) downloadAssets(ctx context.Context, assets []*github.ReleaseAsset, assetDir string, sums checksums, signatures *signatureVerifier, params InParams) error {
+	concurrency := max(params.DownloadConcurrency, 1)
+
+	errs := make([]error, len(assets))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(assets)) {
+		wg.Go(func() {
+			for i := range indexes {
+				errs[i] = c.fetchAsset(ctx, assets[i], filepath.Join(assetDir, *assets[i].Name), sums, params.ChecksumFile, signatures)
+			}
+		})
+	}
+
+	for i := range assets {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// fetchAsset downloads and verifies a single asset, removing whatever was
+// written if either fails.
+func (c *InCommand) fetchAsset(ctx context.Context, asset *github.ReleaseAsset, path string, sums checksums, checksumFile string, signatures *signatureVerifier) error {
+	fmt.Fprintf(c.writer, "downloading asset: %s\n", *asset.Name)
+
+	err := c.downloadAsset(ctx, asset, path)
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to download asset '%s': %w", *asset.Name, err)
+	}
+
+	// Signatures are checked against the keys rather than being listed in
+	// the checksum file they may sign.
+	assetSums := sums
+	if signatures != nil && signatures.isSignature(*asset.Name) {
+		assetSums = nil
+	}
+
+	err = c.verifyAsset(asset, path, assetSums, checksumFile)
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	err = c.verifySignatures(ctx, asset, path, sums, checksumFile, signatures)
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	fmt.Fprintf(c.writer, "downloaded asset: %s\n", *asset.Name)
+
+	return nil
+}
+
+// downloadAsset downloads the asset into a temporary file next to destPath,
+// resuming where it left off if the transfer fails part way, and moves it
+// into place once it is complete.
+func (c *InCommand) downloadAsset(ctx context.Context, asset *github.ReleaseAsset, destPath string) error {
+	tmpPath := destPath + ".download"
+
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
 	defer out.Close()
 
-	content, err := c.github.DownloadReleaseAsset(*asset)
+	var written int64
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			fmt.Fprintf(c.writer, "resuming download of asset %s at byte %d: %s\n", *asset.Name, written, err)
+		}
+
+		var n int64
+		n, err = c.transferAsset(ctx, asset, out, written)
+		written += n
+
+		var interrupted *interruptedError
+		if err == nil || !errors.As(err, &interrupted) || attempt >= maxDownloadResumes || ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = out.Close()
 	if err != nil {
 		return err
 	}
+
+	return os.Rename(tmpPath, destPath)
+}
+
+// transferAsset writes the content of the asset from offset on, returning
+// an interruptedError if the download can be resumed after failing.
+func (c *InCommand) transferAsset(ctx context.Context, asset *github.ReleaseAsset, out io.Writer, offset int64) (int64, error) {
+	content, err := c.github.DownloadReleaseAsset(ctx, *asset, offset)
+	if err != nil {
+		return 0, err
+	}
 	defer content.Close()
 
-	_, err = io.Copy(out, content)
+	body := &readErrorRecorder{reader: content}
+
+	n, err := io.Copy(out, body)
+	if err != nil {
+		if body.err != nil {
+			return n, &interruptedError{err: err}
+		}
+		return n, err
+	}
+
+	if asset.Size != nil {
+		size := int64(*asset.Size)
+		switch {
+		case offset+n < size:
+			return n, &interruptedError{err: fmt.Errorf("received %d of %d bytes", offset+n, size)}
+		case offset+n > size:
+			return n, fmt.Errorf("received %d bytes, but the asset is %d bytes", offset+n, size)
+		}
+	}
+
+	return n, nil
+}
+
+// interruptedError is a failure while receiving the content of an asset,
+// after which the download can be resumed.
+type interruptedError struct {
+	err error
+}
+
+func (e *interruptedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *interruptedError) Unwrap() error {
+	return e.err
+}
+
+// readErrorRecorder records the error of reading from the reader, so that
+// it can be told apart from errors writing what was read.
+type readErrorRecorder struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// verifySignatures checks the signatures of a downloaded asset. Assets
+// verified against a checksum file need not be signed themselves, as the
+// checksum file is.
+func (c *InCommand) verifySignatures(ctx context.Context, asset *github.ReleaseAsset, path string, sums checksums, checksumFile string, signatures *signatureVerifier) error {
+	if signatures == nil || signatures.isSignature(*asset.Name) {
+		return nil
+	}
+
+	if sums != nil {
+		isChecksumFile, err := filepath.Match(checksumFile, *asset.Name)
+		if err != nil {
+			return err
+		}
+
+		if !isChecksumFile {
+			fmt.Fprintf(c.writer, "verified signature of asset: %s through the signed checksum file\n", *asset.Name)
+			return nil
+		}
+	}
+
+	verified, err := signatures.verify(ctx, *asset.Name, func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
 	if err != nil {
 		return err
 	}
 
+	c.reportSignatures(*asset.Name, verified)
+
 	return nil
 }
 
-func (c *InCommand) downloadFile(url, destPath string) error {
+func (c *InCommand) reportSignatures(name string, verified []string) {
+	for _, signature := range verified {
+		fmt.Fprintf(c.writer, "verified signature of asset: %s with %s\n", name, signature)
+	}
+}
+
+// fetchChecksums reads the checksum files matching checksumFile, verifying
+// their signatures if required.
+func (c *InCommand) fetchChecksums(ctx context.Context, assets []*github.ReleaseAsset, checksumFile string, signatures *signatureVerifier) (checksums, error) {
+	sums := checksums{}
+	found := false
+
+	for _, asset := range assets {
+		if asset.State == nil || *asset.State != "uploaded" {
+			continue
+		}
+
+		matches, err := filepath.Match(checksumFile, *asset.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		found = true
+
+		fmt.Fprintf(c.writer, "fetching checksums: %s\n", *asset.Name)
+
+		content, err := c.github.DownloadReleaseAsset(ctx, *asset, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := io.ReadAll(content)
+		content.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if signatures != nil {
+			verified, err := signatures.verify(ctx, *asset.Name, func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			c.reportSignatures(*asset.Name, verified)
+		}
+
+		parsed, err := parseChecksums(*asset.Name, body)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, expected := range parsed {
+			sums[name] = append(sums[name], expected...)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("could not find checksum asset that matches '%s'", checksumFile)
+	}
+
+	return sums, nil
+}
+
+// verifyAsset checks a downloaded asset against the checksum file, if one
+// was given, and against the digest GitHub reports for it, if present.
+func (c *InCommand) verifyAsset(asset *github.ReleaseAsset, path string, sums checksums, checksumFile string) error {
+	var expected []checksum
+
+	if sums != nil {
+		isChecksumFile, err := filepath.Match(checksumFile, *asset.Name)
+		if err != nil {
+			return err
+		}
+
+		if !isChecksumFile {
+			expected = sums[*asset.Name]
+			if len(expected) == 0 {
+				return fmt.Errorf("asset '%s' is missing from the checksum file", *asset.Name)
+			}
+		}
+	}
+
+	if asset.Digest != nil {
+		if digest, ok := assetDigest(*asset.Digest); ok {
+			expected = append(expected, digest)
+		}
+	}
+
+	if len(expected) == 0 {
+		return nil
+	}
+
+	err := verifyChecksums(*asset.Name, path, expected)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.writer, "verified checksum of asset: %s\n", *asset.Name)
+
+	return nil
+}
+
+func (c *InCommand) downloadFile(ctx context.Context, client *http.Client, url, destPath string) error {
 	out, err := os.Create(destPath)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(transferContext(ctx), "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -1,20 +1,45 @@
 package resource_test
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"testing/iotest"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/ghttp"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/google/go-github/v74/github"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"go.yaml.in/yaml/v3"
 
 	resource "github.com/concourse/github-release-resource"
 	"github.com/concourse/github-release-resource/fakes"
@@ -100,6 +125,11 @@ var _ = Describe("In Command", func() {
 		}
 	}
 
+	sha256Hex := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
 	Context("when there is a tagged release", func() {
 		Context("when a present version is specified", func() {
 			BeforeEach(func() {
@@ -127,19 +157,19 @@ var _ = Describe("In Command", func() {
 				})
 
 				It("succeeds", func() {
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 					Ω(inErr).ShouldNot(HaveOccurred())
 				})
 
 				It("returns the fetched version", func() {
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 					Ω(inResponse.Version).Should(Equal(newVersionWithTimestamp(1, "v0.35.0", 1)))
 				})
 
 				It("has some sweet metadata", func() {
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 					Ω(inResponse.Metadata).Should(ConsistOf(
 						resource.MetadataPair{Name: "url", Value: "http://google.com"},
@@ -151,21 +181,24 @@ var _ = Describe("In Command", func() {
 				})
 
 				It("calls #GetReleast with the correct arguments", func() {
-					command.Run(destDir, inRequest)
+					command.Run(context.Background(), destDir, inRequest)
 
-					Ω(githubClient.GetReleaseArgsForCall(0)).Should(Equal(1))
+					_, id := githubClient.GetReleaseArgsForCall(0)
+					Ω(id).Should(Equal(1))
 				})
 
 				It("downloads only the files that match the globs", func() {
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 					Expect(githubClient.DownloadReleaseAssetCallCount()).To(Equal(2))
-					Ω(githubClient.DownloadReleaseAssetArgsForCall(0)).Should(Equal(*buildAsset(0, "example.txt")))
-					Ω(githubClient.DownloadReleaseAssetArgsForCall(1)).Should(Equal(*buildAsset(1, "example.rtf")))
+					_, asset, _ := githubClient.DownloadReleaseAssetArgsForCall(0)
+					Ω(asset).Should(Equal(*buildAsset(0, "example.txt")))
+					_, asset, _ = githubClient.DownloadReleaseAssetArgsForCall(1)
+					Ω(asset).Should(Equal(*buildAsset(1, "example.rtf")))
 				})
 
 				It("does create the body, tag, version, and url files", func() {
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 					contents, err := os.ReadFile(path.Join(destDir, "tag"))
 					Ω(err).ShouldNot(HaveOccurred())
@@ -199,17 +232,17 @@ var _ = Describe("In Command", func() {
 						}
 						githubClient.GetReleaseReturns(buildRelease(1, "package-0.35.0", false), nil)
 						githubClient.ResolveTagToCommitSHAReturns("f28085a4a8f744da83411f5e09fd7b1709149eee", nil)
-						inResponse, inErr = command.Run(destDir, inRequest)
+						inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 					})
 
 					It("succeeds", func() {
-						inResponse, inErr = command.Run(destDir, inRequest)
+						inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 						Expect(inErr).ToNot(HaveOccurred())
 					})
 
 					It("does create the tag, version, and url files", func() {
-						inResponse, inErr = command.Run(destDir, inRequest)
+						inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 						contents, err := os.ReadFile(path.Join(destDir, "tag"))
 						Ω(err).ShouldNot(HaveOccurred())
@@ -225,6 +258,42 @@ var _ = Describe("In Command", func() {
 					})
 				})
 
+				Context("when there is a version scheme", func() {
+					BeforeEach(func() {
+						inRequest.Source = resource.Source{
+							VersionScheme: "pep440",
+						}
+						githubClient.GetReleaseReturns(buildRelease(1, "v1.0-RC.1", false), nil)
+					})
+
+					It("writes the version in the canonical form of the scheme", func() {
+						inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+						Ω(inErr).ShouldNot(HaveOccurred())
+
+						contents, err := os.ReadFile(path.Join(destDir, "version"))
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(string(contents)).Should(Equal("1.0rc1"))
+					})
+
+					It("writes the version as it is in the tag if it does not parse", func() {
+						githubClient.GetReleaseReturns(buildRelease(1, "v1.0-final-final", false), nil)
+
+						inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+						Ω(inErr).ShouldNot(HaveOccurred())
+
+						contents, err := os.ReadFile(path.Join(destDir, "version"))
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(string(contents)).Should(Equal("1.0-final-final"))
+					})
+
+					It("fails on an unknown scheme", func() {
+						inRequest.Source.VersionScheme = "roman"
+
+						inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+						Ω(inErr).Should(MatchError(ContainSubstring("unknown version_scheme 'roman'")))
+					})
+				})
+
 				Context("when include_source_tarball is true", func() {
 					var tarballUrl *url.URL
 
@@ -251,19 +320,19 @@ var _ = Describe("In Command", func() {
 							})
 
 							It("succeeds", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								Expect(inErr).ToNot(HaveOccurred())
 							})
 
 							It("downloads the source tarball", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								Expect(githubServer.ReceivedRequests()).To(HaveLen(1))
 							})
 
 							It("saves the source tarball in the destination directory", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								fileContents, err := os.ReadFile(filepath.Join(destDir, "source.tar.gz"))
 								fContents := string(fileContents)
@@ -273,7 +342,7 @@ var _ = Describe("In Command", func() {
 
 							It("saves the source tarball in the assets directory, if desired", func() {
 								inRequest.Source.AssetDir = true
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								fileContents, err := os.ReadFile(filepath.Join(destDir, "assets", "source.tar.gz"))
 								fContents := string(fileContents)
@@ -293,7 +362,7 @@ var _ = Describe("In Command", func() {
 							})
 
 							It("returns an appropriate error", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								Expect(inErr).To(MatchError("failed to download file `source.tar.gz`: HTTP status 500"))
 							})
@@ -308,7 +377,7 @@ var _ = Describe("In Command", func() {
 						})
 
 						It("returns the error", func() {
-							inResponse, inErr = command.Run(destDir, inRequest)
+							inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 							Expect(inErr).To(Equal(disaster))
 						})
@@ -341,19 +410,19 @@ var _ = Describe("In Command", func() {
 							})
 
 							It("succeeds", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								Expect(inErr).ToNot(HaveOccurred())
 							})
 
 							It("downloads the source zip", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								Expect(githubServer.ReceivedRequests()).To(HaveLen(1))
 							})
 
 							It("saves the source zip in the destination directory", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								fileContents, err := os.ReadFile(filepath.Join(destDir, "source.zip"))
 								fContents := string(fileContents)
@@ -363,7 +432,7 @@ var _ = Describe("In Command", func() {
 
 							It("saves the source tarball in the assets directory, if desired", func() {
 								inRequest.Source.AssetDir = true
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								fileContents, err := os.ReadFile(filepath.Join(destDir, "assets", "source.zip"))
 								fContents := string(fileContents)
@@ -383,7 +452,7 @@ var _ = Describe("In Command", func() {
 							})
 
 							It("returns an appropriate error", func() {
-								inResponse, inErr = command.Run(destDir, inRequest)
+								inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 								Expect(inErr).To(MatchError("failed to download file `source.zip`: HTTP status 500"))
 							})
@@ -398,7 +467,7 @@ var _ = Describe("In Command", func() {
 						})
 
 						It("returns the error", func() {
-							inResponse, inErr = command.Run(destDir, inRequest)
+							inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 
 							Expect(inErr).To(Equal(disaster))
 						})
@@ -409,7 +478,7 @@ var _ = Describe("In Command", func() {
 			Context("when no globs are specified", func() {
 				BeforeEach(func() {
 					inRequest.Source = resource.Source{}
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 				})
 
 				It("succeeds", func() {
@@ -431,9 +500,12 @@ var _ = Describe("In Command", func() {
 				})
 
 				It("downloads all of the files", func() {
-					Ω(githubClient.DownloadReleaseAssetArgsForCall(0)).Should(Equal(*buildAsset(0, "example.txt")))
-					Ω(githubClient.DownloadReleaseAssetArgsForCall(1)).Should(Equal(*buildAsset(1, "example.rtf")))
-					Ω(githubClient.DownloadReleaseAssetArgsForCall(2)).Should(Equal(*buildAsset(2, "example.wtf")))
+					_, asset, _ := githubClient.DownloadReleaseAssetArgsForCall(0)
+					Ω(asset).Should(Equal(*buildAsset(0, "example.txt")))
+					_, asset, _ = githubClient.DownloadReleaseAssetArgsForCall(1)
+					Ω(asset).Should(Equal(*buildAsset(1, "example.rtf")))
+					_, asset, _ = githubClient.DownloadReleaseAssetArgsForCall(2)
+					Ω(asset).Should(Equal(*buildAsset(2, "example.wtf")))
 					Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(3))
 				})
 			})
@@ -441,7 +513,7 @@ var _ = Describe("In Command", func() {
 			Context("when downloading an asset fails", func() {
 				BeforeEach(func() {
 					githubClient.DownloadReleaseAssetReturns(nil, errors.New("not this time"))
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 				})
 
 				It("returns an error", func() {
@@ -449,18 +521,966 @@ var _ = Describe("In Command", func() {
 				})
 			})
 
+			Context("when download_concurrency is set", func() {
+				BeforeEach(func() {
+					inRequest.Params = resource.InParams{
+						DownloadConcurrency: 3,
+					}
+				})
+
+				It("downloads the assets in parallel", func() {
+					started := make(chan string, 3)
+					release := make(chan struct{})
+
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, asset github.ReleaseAsset, _ int64) (io.ReadCloser, error) {
+						started <- *asset.Name
+						<-release
+						return io.NopCloser(bytes.NewBufferString(*asset.Name)), nil
+					}
+
+					done := make(chan error)
+					go func() {
+						_, err := command.Run(context.Background(), destDir, inRequest)
+						done <- err
+					}()
+
+					for range 3 {
+						Eventually(started).Should(Receive())
+					}
+					close(release)
+
+					Eventually(done).Should(Receive(BeNil()))
+
+					for _, name := range []string{"example.txt", "example.rtf", "example.wtf"} {
+						Ω(os.ReadFile(filepath.Join(destDir, name))).Should(Equal([]byte(name)))
+					}
+				})
+
+				It("reports every failed asset and cleans up after them", func() {
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, asset github.ReleaseAsset, _ int64) (io.ReadCloser, error) {
+						switch *asset.Name {
+						case "example.txt":
+							return nil, errors.New("gone")
+						case "example.rtf":
+							return io.NopCloser(io.MultiReader(
+								bytes.NewBufferString("partial"),
+								iotest.ErrReader(errors.New("connection reset")),
+							)), nil
+						default:
+							return io.NopCloser(bytes.NewBufferString("complete")), nil
+						}
+					}
+
+					_, inErr = command.Run(context.Background(), destDir, inRequest)
+					Ω(inErr).Should(MatchError(ContainSubstring("failed to download asset 'example.txt': gone")))
+					Ω(inErr).Should(MatchError(ContainSubstring("failed to download asset 'example.rtf': connection reset")))
+
+					Ω(filepath.Join(destDir, "example.txt")).ShouldNot(BeAnExistingFile())
+					Ω(filepath.Join(destDir, "example.rtf")).ShouldNot(BeAnExistingFile())
+					Ω(filepath.Join(destDir, "example.wtf")).Should(BeAnExistingFile())
+				})
+			})
+
+			Context("when writing the release's metadata", func() {
+				var release *github.RepositoryRelease
+
+				BeforeEach(func() {
+					release = buildRelease(1, "v1.2.3-rc.1+build.5", false)
+					release.NodeID = github.Ptr("RE_kwDOAbc")
+					release.TargetCommitish = github.Ptr("main")
+					release.Author = &github.User{Login: github.Ptr("octocat")}
+					release.Assets = []*github.ReleaseAsset{buildAsset(0, "example.txt")}
+					githubClient.GetReleaseReturns(release, nil)
+					githubClient.GetLatestReleaseReturns(&github.RepositoryRelease{ID: github.Int64(1)}, nil)
+
+					inRequest.Version.Tag = "v1.2.3-rc.1+build.5"
+				})
+
+				JustBeforeEach(func() {
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+				})
+
+				expectedJSON := `{
+					"id": 1,
+					"node_id": "RE_kwDOAbc",
+					"name": "release-name",
+					"tag": "v1.2.3-rc.1+build.5",
+					"target_commitish": "main",
+					"commit_sha": "f28085a4a8f744da83411f5e09fd7b1709149eee",
+					"author": "octocat",
+					"draft": false,
+					"prerelease": false,
+					"latest": null,
+					"created_at": "2018-01-01T00:00:00Z",
+					"published_at": "2018-01-01T00:00:00Z",
+					"url": "http://google.com",
+					"body": "*markdown*",
+					"assets": [
+						{
+							"id": 0,
+							"name": "example.txt",
+							"size": 0,
+							"content_type": "",
+							"download_url": "",
+							"state": "uploaded"
+						}
+					],
+					"version": {
+						"version": "1.2.3-rc.1+build.5",
+						"major": 1,
+						"minor": 2,
+						"patch": 3,
+						"prerelease": "rc.1",
+						"build": "build.5",
+						"next_major": "2.0.0",
+						"next_minor": "1.3.0",
+						"next_patch": "1.2.3"
+					}
+				}`
+
+				It("writes release.json", func() {
+					Ω(inErr).ShouldNot(HaveOccurred())
+					Ω(os.ReadFile(filepath.Join(destDir, "release.json"))).Should(MatchJSON(expectedJSON))
+				})
+
+				It("does not write release.yaml", func() {
+					Ω(filepath.Join(destDir, "release.yaml")).ShouldNot(BeAnExistingFile())
+				})
+
+				Context("when include_release_yaml is set", func() {
+					BeforeEach(func() {
+						inRequest.Params.IncludeReleaseYAML = true
+					})
+
+					It("writes the same metadata to release.yaml", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+
+						content, err := os.ReadFile(filepath.Join(destDir, "release.yaml"))
+						Ω(err).ShouldNot(HaveOccurred())
+
+						// Round trip through JSON so that timestamps compare
+						// as strings.
+						var metadata map[string]any
+						Ω(yaml.Unmarshal(content, &metadata)).Should(Succeed())
+						Ω(json.Marshal(metadata)).Should(MatchJSON(expectedJSON))
+					})
+				})
+
+				It("does not look up the latest release", func() {
+					Ω(githubClient.GetLatestReleaseCallCount()).Should(BeZero())
+				})
+
+				Context("when include_latest is set", func() {
+					BeforeEach(func() {
+						inRequest.Params.IncludeLatest = true
+					})
+
+					It("marks the latest release", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+						Ω(githubClient.GetLatestReleaseCallCount()).Should(Equal(1))
+						Ω(os.ReadFile(filepath.Join(destDir, "release.json"))).Should(ContainSubstring(`"latest": true`))
+					})
+
+					Context("when another release is the latest", func() {
+						BeforeEach(func() {
+							githubClient.GetLatestReleaseReturns(&github.RepositoryRelease{ID: github.Int64(2)}, nil)
+						})
+
+						It("is not marked as the latest", func() {
+							Ω(os.ReadFile(filepath.Join(destDir, "release.json"))).Should(ContainSubstring(`"latest": false`))
+						})
+					})
+
+					Context("when the latest release cannot be determined", func() {
+						BeforeEach(func() {
+							githubClient.GetLatestReleaseReturns(nil, errors.New("disaster"))
+						})
+
+						It("fails", func() {
+							Ω(inErr).Should(MatchError("disaster"))
+						})
+					})
+
+					Context("when the release is a draft", func() {
+						BeforeEach(func() {
+							release.Draft = github.Bool(true)
+						})
+
+						It("does not look up the latest release", func() {
+							Ω(inErr).ShouldNot(HaveOccurred())
+							Ω(githubClient.GetLatestReleaseCallCount()).Should(BeZero())
+							Ω(os.ReadFile(filepath.Join(destDir, "release.json"))).Should(ContainSubstring(`"latest": false`))
+						})
+					})
+
+					Context("when the release is a prerelease", func() {
+						BeforeEach(func() {
+							release.Prerelease = github.Bool(true)
+						})
+
+						It("does not look up the latest release", func() {
+							Ω(inErr).ShouldNot(HaveOccurred())
+							Ω(githubClient.GetLatestReleaseCallCount()).Should(BeZero())
+							Ω(os.ReadFile(filepath.Join(destDir, "release.json"))).Should(ContainSubstring(`"latest": false`))
+						})
+					})
+				})
+
+				It("writes the version's components", func() {
+					Ω(inErr).ShouldNot(HaveOccurred())
+
+					for name, content := range map[string]string{
+						"version_major":      "1",
+						"version_minor":      "2",
+						"version_patch":      "3",
+						"version_prerelease": "rc.1",
+						"version_build":      "build.5",
+						"next_major":         "2.0.0",
+						"next_minor":         "1.3.0",
+						"next_patch":         "1.2.3",
+					} {
+						Ω(os.ReadFile(filepath.Join(destDir, name))).Should(Equal([]byte(content)), name)
+					}
+				})
+
+				DescribeTable("bumping the version",
+					func(tag, nextMajor, nextMinor, nextPatch string) {
+						release.TagName = github.Ptr(tag)
+
+						_, err := command.Run(context.Background(), destDir, inRequest)
+						Ω(err).ShouldNot(HaveOccurred())
+
+						Ω(os.ReadFile(filepath.Join(destDir, "next_major"))).Should(Equal([]byte(nextMajor)))
+						Ω(os.ReadFile(filepath.Join(destDir, "next_minor"))).Should(Equal([]byte(nextMinor)))
+						Ω(os.ReadFile(filepath.Join(destDir, "next_patch"))).Should(Equal([]byte(nextPatch)))
+					},
+					Entry("a release", "v1.2.3", "2.0.0", "1.3.0", "1.2.4"),
+					Entry("a release with build metadata", "v1.2.3+build.5", "2.0.0", "1.3.0", "1.2.4"),
+					Entry("a patch prerelease", "v1.2.3-rc.1", "2.0.0", "1.3.0", "1.2.3"),
+					Entry("a minor prerelease", "v1.3.0-rc.1", "2.0.0", "1.3.0", "1.3.0"),
+					Entry("a major prerelease", "v2.0.0-rc.1", "2.0.0", "2.0.0", "2.0.0"),
+				)
+
+				DescribeTable("versions semver is lenient about",
+					func(tag string) {
+						release.TagName = github.Ptr(tag)
+						dir := GinkgoT().TempDir()
+
+						_, err := command.Run(context.Background(), dir, inRequest)
+						Ω(err).ShouldNot(HaveOccurred())
+
+						for _, name := range []string{"version_major", "version_minor", "version_patch", "next_major", "next_minor", "next_patch"} {
+							Ω(filepath.Join(dir, name)).ShouldNot(BeAnExistingFile(), name)
+						}
+					},
+					Entry("a version without a patch", "v1.2"),
+					Entry("a calendar version with leading zeroes", "v2024.05.17"),
+				)
+
+				Context("when the version is not semver", func() {
+					BeforeEach(func() {
+						release.TagName = github.Ptr("nightly")
+					})
+
+					It("leaves out the version components", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+
+						var metadata map[string]any
+						content, err := os.ReadFile(filepath.Join(destDir, "release.json"))
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(json.Unmarshal(content, &metadata)).Should(Succeed())
+						Ω(metadata).Should(HaveKeyWithValue("version", BeNil()))
+					})
+
+					It("does not write the version's components", func() {
+						Ω(filepath.Join(destDir, "version_major")).ShouldNot(BeAnExistingFile())
+						Ω(filepath.Join(destDir, "next_patch")).ShouldNot(BeAnExistingFile())
+					})
+				})
+			})
+
+			Context("when verify is set", func() {
+				var (
+					contents map[string]string
+					output   *gbytes.Buffer
+
+					gpgEntity *openpgp.Entity
+					cosignKey *ecdsa.PrivateKey
+					edKey     ed25519.PrivateKey
+				)
+
+				BeforeEach(func() {
+					output = gbytes.NewBuffer()
+					command = resource.NewInCommand(githubClient, output)
+
+					var err error
+					gpgEntity, err = openpgp.NewEntity("Release Bot", "", "bot@example.com", nil)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					cosignKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					_, edKey, err = ed25519.GenerateKey(rand.Reader)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					contents = map[string]string{
+						"app.tgz":              "app",
+						"app.tgz.asc":          gpgSignature(gpgEntity, "app", true),
+						"tool.bin":             "tool",
+						"tool.bin.sig":         base64.StdEncoding.EncodeToString(cosignSignature(cosignKey, "tool")),
+						"lib.so":               "lib",
+						"lib.so.sigstore.json": sigstoreBundleFor(cosignKey, "lib"),
+					}
+
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, asset github.ReleaseAsset, _ int64) (io.ReadCloser, error) {
+						return io.NopCloser(strings.NewReader(contents[*asset.Name])), nil
+					}
+
+					inRequest.Params.Globs = []string{"app.tgz", "tool.bin", "lib.so"}
+					inRequest.Params.Verify = resource.VerifyParams{
+						GPGKeys:    []string{gpgPublicKey(gpgEntity)},
+						CosignKeys: []string{cosignPublicKey(cosignKey.Public())},
+					}
+				})
+
+				JustBeforeEach(func() {
+					var assets []*github.ReleaseAsset
+					for i, name := range slices.Sorted(maps.Keys(contents)) {
+						assets = append(assets, buildAsset(int64(i), name))
+					}
+					githubClient.ListReleaseAssetsReturns(assets, nil)
+
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+				})
+
+				It("downloads the assets and reports which signature verified them", func() {
+					Ω(inErr).ShouldNot(HaveOccurred())
+
+					Ω(os.ReadFile(filepath.Join(destDir, "app.tgz"))).Should(Equal([]byte("app")))
+					Ω(os.ReadFile(filepath.Join(destDir, "tool.bin"))).Should(Equal([]byte("tool")))
+					Ω(os.ReadFile(filepath.Join(destDir, "lib.so"))).Should(Equal([]byte("lib")))
+
+					fingerprint := strings.ToUpper(hex.EncodeToString(gpgEntity.PrimaryKey.Fingerprint))
+					Ω(string(output.Contents())).Should(ContainSubstring("verified signature of asset: app.tgz with app.tgz.asc gpg signature by " + fingerprint + " (Release Bot <bot@example.com>)"))
+					Ω(string(output.Contents())).Should(MatchRegexp(`verified signature of asset: tool.bin with tool.bin.sig cosign signature by key sha256:[0-9a-f]{16}`))
+					Ω(string(output.Contents())).Should(MatchRegexp(`verified signature of asset: lib.so with lib.so.sigstore.json sigstore bundle by key sha256:[0-9a-f]{16}`))
+				})
+
+				Context("with binary GPG and Ed25519 signatures", func() {
+					BeforeEach(func() {
+						contents = map[string]string{
+							"app.tgz":      "app",
+							"app.tgz.sig":  gpgSignature(gpgEntity, "app", false),
+							"tool.bin":     "tool",
+							"tool.bin.sig": base64.StdEncoding.EncodeToString(ed25519.Sign(edKey, []byte("tool"))),
+						}
+
+						inRequest.Params.Globs = nil
+						inRequest.Params.Verify.CosignKeys = []string{cosignPublicKey(edKey.Public())}
+					})
+
+					It("verifies them, leaving the signature assets unsigned", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+						Ω(string(output.Contents())).Should(ContainSubstring("verified signature of asset: app.tgz with app.tgz.sig gpg signature by"))
+						Ω(string(output.Contents())).Should(ContainSubstring("verified signature of asset: tool.bin with tool.bin.sig cosign signature by key"))
+						Ω(filepath.Join(destDir, "tool.bin.sig")).Should(BeAnExistingFile())
+					})
+				})
+
+				Context("when an asset is not signed", func() {
+					BeforeEach(func() {
+						delete(contents, "tool.bin.sig")
+					})
+
+					It("fails and removes it", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("asset 'tool.bin' is not signed: found none of tool.bin.asc, tool.bin.sig, tool.bin.sigstore.json, tool.bin.sigstore, tool.bin.bundle")))
+						Ω(filepath.Join(destDir, "tool.bin")).ShouldNot(BeAnExistingFile())
+						Ω(filepath.Join(destDir, "app.tgz")).Should(BeAnExistingFile())
+					})
+				})
+
+				Context("when an unsigned asset merely ends in a signature suffix", func() {
+					BeforeEach(func() {
+						contents["repo.bundle"] = "repo"
+						inRequest.Params.Globs = append(inRequest.Params.Globs, "repo.bundle")
+					})
+
+					It("fails and removes it", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("asset 'repo.bundle' is not signed: found none of repo.bundle.asc")))
+						Ω(filepath.Join(destDir, "repo.bundle")).ShouldNot(BeAnExistingFile())
+					})
+				})
+
+				Context("when an asset is signed by another key", func() {
+					BeforeEach(func() {
+						other, err := openpgp.NewEntity("Mallory", "", "mallory@example.com", nil)
+						Ω(err).ShouldNot(HaveOccurred())
+						contents["app.tgz.asc"] = gpgSignature(other, "app", true)
+					})
+
+					It("fails", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("invalid signature 'app.tgz.asc' for asset 'app.tgz': gpg:")))
+						Ω(filepath.Join(destDir, "app.tgz")).ShouldNot(BeAnExistingFile())
+					})
+				})
+
+				Context("when an asset does not match its signature", func() {
+					BeforeEach(func() {
+						contents["tool.bin"] = "tampered"
+					})
+
+					It("fails", func() {
+						Ω(inErr).Should(MatchError(And(
+							ContainSubstring("invalid signature 'tool.bin.sig' for asset 'tool.bin'"),
+							ContainSubstring("not signed by any of the cosign keys"),
+						)))
+					})
+				})
+
+				Context("when an asset does not match the digest in its bundle", func() {
+					BeforeEach(func() {
+						contents["lib.so"] = "tampered"
+					})
+
+					It("fails", func() {
+						W := ContainSubstring("invalid signature 'lib.so.sigstore.json' for asset 'lib.so': digest does not match the asset")
+						Ω(inErr).Should(MatchError(W))
+					})
+				})
+
+				Context("when one of several signatures is bad", func() {
+					BeforeEach(func() {
+						contents["app.tgz.sigstore.json"] = sigstoreBundleFor(cosignKey, "not the app")
+					})
+
+					It("fails", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("invalid signature 'app.tgz.sigstore.json' for asset 'app.tgz'")))
+					})
+				})
+
+				Context("when a key is invalid", func() {
+					BeforeEach(func() {
+						inRequest.Params.Verify.CosignKeys = []string{"not a key"}
+					})
+
+					It("fails before downloading anything", func() {
+						Ω(inErr).Should(MatchError("invalid cosign key at index 0: no PEM encoded public key found"))
+						Ω(githubClient.DownloadReleaseAssetCallCount()).Should(BeZero())
+					})
+				})
+
+				Context("with a signed checksum file", func() {
+					BeforeEach(func() {
+						sums := sha256Hex("app") + "  app.tgz\n" + sha256Hex("tool") + "  tool.bin\n"
+						contents = map[string]string{
+							"app.tgz":        "app",
+							"tool.bin":       "tool",
+							"SHA256SUMS":     sums,
+							"SHA256SUMS.asc": gpgSignature(gpgEntity, sums, true),
+						}
+
+						inRequest.Params.Globs = nil
+						inRequest.Params.ChecksumFile = "SHA256SUMS"
+					})
+
+					It("verifies the assets through it", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+						Ω(string(output.Contents())).Should(ContainSubstring("verified signature of asset: SHA256SUMS with SHA256SUMS.asc gpg signature by"))
+						Ω(string(output.Contents())).Should(ContainSubstring("verified signature of asset: app.tgz through the signed checksum file"))
+						Ω(string(output.Contents())).Should(ContainSubstring("verified signature of asset: tool.bin through the signed checksum file"))
+					})
+
+					Context("when the checksum file is not signed", func() {
+						BeforeEach(func() {
+							delete(contents, "SHA256SUMS.asc")
+						})
+
+						It("fails", func() {
+							Ω(inErr).Should(MatchError(ContainSubstring("asset 'SHA256SUMS' is not signed")))
+						})
+					})
+				})
+			})
+
+			Context("when skip_download is set", func() {
+				BeforeEach(func() {
+					inRequest.Params = resource.InParams{
+						SkipDownload:         true,
+						IncludeSourceTarball: true,
+						IncludeSourceZip:     true,
+					}
+				})
+
+				JustBeforeEach(func() {
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+				})
+
+				It("succeeds with the release's version and metadata", func() {
+					Ω(inErr).ShouldNot(HaveOccurred())
+					Ω(inResponse.Version).Should(Equal(newVersionWithTimestamp(1, "v0.35.0", 1)))
+					Ω(inResponse.Metadata).ShouldNot(BeEmpty())
+				})
+
+				It("writes the release's files", func() {
+					for _, name := range []string{"tag", "version", "commit_sha", "body", "url", "timestamp"} {
+						Ω(filepath.Join(destDir, name)).Should(BeAnExistingFile())
+					}
+				})
+
+				It("downloads nothing", func() {
+					Ω(githubClient.ListReleaseAssetsCallCount()).Should(BeZero())
+					Ω(githubClient.DownloadReleaseAssetCallCount()).Should(BeZero())
+					Ω(githubClient.GetTarballLinkCallCount()).Should(BeZero())
+					Ω(githubClient.GetZipballLinkCallCount()).Should(BeZero())
+				})
+
+				Context("and asset_manifest is set", func() {
+					BeforeEach(func() {
+						inRequest.Params.AssetManifest = true
+					})
+
+					It("still writes the manifest", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+						Ω(filepath.Join(destDir, "assets.json")).Should(BeAnExistingFile())
+						Ω(githubClient.DownloadReleaseAssetCallCount()).Should(BeZero())
+					})
+				})
+			})
+
+			Context("when asset_manifest is set", func() {
+				BeforeEach(func() {
+					txt := buildAsset(0, "example.txt")
+					txt.Size = github.Int(12)
+					txt.ContentType = github.Ptr("text/plain")
+					txt.BrowserDownloadURL = github.Ptr("https://github.com/concourse/concourse/releases/download/v0.35.0/example.txt")
+					txt.Digest = github.Ptr("sha256:" + sha256Hex("some-content"))
+
+					githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+						txt,
+						buildFailedAsset(3, "example.doc"),
+					}, nil)
+
+					inRequest.Params = resource.InParams{
+						AssetManifest: true,
+						Globs:         []string{"*.txt"},
+						ChecksumFile:  "SHA256SUMS",
+					}
+				})
+
+				JustBeforeEach(func() {
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+				})
+
+				It("describes every asset in assets.json", func() {
+					Ω(inErr).ShouldNot(HaveOccurred())
+
+					contents, err := os.ReadFile(filepath.Join(destDir, "assets.json"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(contents).Should(MatchJSON(`[
+						{
+							"id": 0,
+							"name": "example.txt",
+							"size": 12,
+							"content_type": "text/plain",
+							"download_url": "https://github.com/concourse/concourse/releases/download/v0.35.0/example.txt",
+							"digest": "sha256:` + sha256Hex("some-content") + `",
+							"state": "uploaded"
+						},
+						{
+							"id": 3,
+							"name": "example.doc",
+							"size": 0,
+							"content_type": "",
+							"download_url": "",
+							"state": "starter"
+						}
+					]`))
+				})
+
+				It("does not download the assets", func() {
+					Ω(githubClient.DownloadReleaseAssetCallCount()).Should(BeZero())
+					Ω(filepath.Join(destDir, "example.txt")).ShouldNot(BeAnExistingFile())
+				})
+
+				Context("when the release has no assets", func() {
+					BeforeEach(func() {
+						githubClient.ListReleaseAssetsReturns(nil, nil)
+					})
+
+					It("writes an empty list", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+						Ω(os.ReadFile(filepath.Join(destDir, "assets.json"))).Should(MatchJSON(`[]`))
+					})
+				})
+			})
+
+			Context("when extract is set", func() {
+				var archives map[string][]byte
+
+				BeforeEach(func() {
+					archives = map[string][]byte{
+						"app.tar.gz": tarArchive("gzip",
+							archiveEntry{name: "app-1.0/"},
+							archiveEntry{name: "app-1.0/bin/app", content: "binary", mode: 0755},
+							archiveEntry{name: "app-1.0/README", content: "readme"},
+							archiveEntry{name: "app-1.0/bin/latest", link: "app"},
+						),
+						"app.tar.xz":  tarArchive("xz", archiveEntry{name: "xz.txt", content: "xz"}),
+						"app.tar.zst": tarArchive("zstd", archiveEntry{name: "zstd.txt", content: "zstd"}),
+						"app.tar":     tarArchive("", archiveEntry{name: "plain.txt", content: "plain"}),
+						"app.zip": zipArchive(
+							archiveEntry{name: "docs/index.html", content: "<html>"},
+							archiveEntry{name: "docs/current", link: "index.html"},
+						),
+					}
+
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, asset github.ReleaseAsset, _ int64) (io.ReadCloser, error) {
+						return io.NopCloser(bytes.NewReader(archives[*asset.Name])), nil
+					}
+				})
+
+				JustBeforeEach(func() {
+					var assets []*github.ReleaseAsset
+					for i, name := range slices.Sorted(maps.Keys(archives)) {
+						assets = append(assets, buildAsset(int64(i), name))
+					}
+					githubClient.ListReleaseAssetsReturns(assets, nil)
+
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+				})
+
+				Context("with a glob, subdirectory and strip_components", func() {
+					BeforeEach(func() {
+						inRequest.Params.Extract = []resource.ExtractParams{
+							{Glob: "*.tar.gz", Into: "app", StripComponents: 1},
+						}
+					})
+
+					It("extracts the matching archives", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+
+						Ω(os.ReadFile(filepath.Join(destDir, "app", "bin", "app"))).Should(Equal([]byte("binary")))
+						Ω(os.ReadFile(filepath.Join(destDir, "app", "README"))).Should(Equal([]byte("readme")))
+						Ω(os.ReadFile(filepath.Join(destDir, "app", "bin", "latest"))).Should(Equal([]byte("binary")))
+
+						info, err := os.Stat(filepath.Join(destDir, "app", "bin", "app"))
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0755)))
+					})
+
+					It("keeps the archives and leaves others alone", func() {
+						Ω(filepath.Join(destDir, "app.tar.gz")).Should(BeAnExistingFile())
+						Ω(filepath.Join(destDir, "app.zip")).Should(BeAnExistingFile())
+						Ω(filepath.Join(destDir, "docs")).ShouldNot(BeAnExistingFile())
+					})
+				})
+
+				Context("with every format", func() {
+					BeforeEach(func() {
+						inRequest.Params.Extract = []resource.ExtractParams{
+							{Glob: "app.tar.gz", Into: "gz"},
+							{Glob: "*", Into: "all", DeleteArchive: true},
+						}
+					})
+
+					It("extracts each archive with the first matching glob", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+
+						Ω(filepath.Join(destDir, "gz", "app-1.0", "README")).Should(BeAnExistingFile())
+						Ω(os.ReadFile(filepath.Join(destDir, "all", "xz.txt"))).Should(Equal([]byte("xz")))
+						Ω(os.ReadFile(filepath.Join(destDir, "all", "zstd.txt"))).Should(Equal([]byte("zstd")))
+						Ω(os.ReadFile(filepath.Join(destDir, "all", "plain.txt"))).Should(Equal([]byte("plain")))
+						Ω(os.ReadFile(filepath.Join(destDir, "all", "docs", "current"))).Should(Equal([]byte("<html>")))
+					})
+
+					It("deletes the archives if asked to", func() {
+						Ω(filepath.Join(destDir, "app.tar.gz")).Should(BeAnExistingFile())
+						Ω(filepath.Join(destDir, "app.zip")).ShouldNot(BeAnExistingFile())
+						Ω(filepath.Join(destDir, "app.tar.xz")).ShouldNot(BeAnExistingFile())
+					})
+				})
+
+				Context("with an archive entry outside of the destination", func() {
+					BeforeEach(func() {
+						archives = map[string][]byte{
+							"app.tar.gz": tarArchive("gzip", archiveEntry{name: "../../evil", content: "evil"}),
+						}
+						inRequest.Params.Extract = []resource.ExtractParams{{Glob: "*.tar.gz"}}
+					})
+
+					It("fails without writing it", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("invalid archive entry '../../evil': path escapes the destination")))
+						Ω(filepath.Join(tmpDir, "evil")).ShouldNot(BeAnExistingFile())
+					})
+				})
+
+				Context("with a symlink out of the destination", func() {
+					BeforeEach(func() {
+						archives = map[string][]byte{
+							"app.zip": zipArchive(archiveEntry{name: "dir/passwd", link: "../../etc/passwd"}),
+						}
+						inRequest.Params.Extract = []resource.ExtractParams{{Glob: "*.zip"}}
+					})
+
+					It("fails", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("invalid symlink 'dir/passwd': target '../../etc/passwd' escapes the destination")))
+					})
+				})
+
+				Context("with a symlink that goes up out of another symlink", func() {
+					BeforeEach(func() {
+						archives = map[string][]byte{
+							"app.tar": tarArchive("",
+								archiveEntry{name: "sub/up", link: ".."},
+								archiveEntry{name: "escape", link: "sub/up/.."},
+							),
+						}
+						inRequest.Params.Extract = []resource.ExtractParams{{Glob: "*.tar"}}
+					})
+
+					It("fails", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("invalid symlink 'escape'")))
+					})
+				})
+
+				Context("with an entry written through a symlink out of the destination", func() {
+					BeforeEach(func() {
+						Ω(os.MkdirAll(destDir, 0755)).Should(Succeed())
+						Ω(os.Symlink(tmpDir, filepath.Join(destDir, "out"))).Should(Succeed())
+
+						archives = map[string][]byte{
+							"app.tar": tarArchive("", archiveEntry{name: "out/evil", content: "evil"}),
+						}
+						inRequest.Params.Extract = []resource.ExtractParams{{Glob: "*.tar"}}
+					})
+
+					It("fails without writing it", func() {
+						Ω(inErr).Should(HaveOccurred())
+						Ω(filepath.Join(tmpDir, "evil")).ShouldNot(BeAnExistingFile())
+					})
+				})
+
+				Context("with a destination outside of the destination directory", func() {
+					BeforeEach(func() {
+						inRequest.Params.Extract = []resource.ExtractParams{{Glob: "*.tar.gz", Into: "../elsewhere"}}
+					})
+
+					It("fails before downloading anything", func() {
+						Ω(inErr).Should(MatchError("invalid extract destination '../elsewhere': must be a relative path within the destination directory"))
+						Ω(githubClient.DownloadReleaseAssetCallCount()).Should(BeZero())
+					})
+				})
+
+				Context("with an unknown archive format", func() {
+					BeforeEach(func() {
+						archives = map[string][]byte{"app.rar": []byte("rar")}
+						inRequest.Params.Extract = []resource.ExtractParams{{Glob: "*"}}
+					})
+
+					It("fails", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("could not determine the archive format of 'app.rar'")))
+					})
+				})
+			})
+
+			Context("when a download is interrupted", func() {
+				BeforeEach(func() {
+					inRequest.Params = resource.InParams{
+						Globs: []string{"example.txt"},
+					}
+
+					size := len("hello world")
+					assets := []*github.ReleaseAsset{buildAsset(0, "example.txt")}
+					assets[0].Size = &size
+					githubClient.ListReleaseAssetsReturns(assets, nil)
+				})
+
+				It("resumes the download where it left off", func() {
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, _ github.ReleaseAsset, offset int64) (io.ReadCloser, error) {
+						if offset == 0 {
+							return io.NopCloser(io.MultiReader(
+								bytes.NewBufferString("hello "),
+								iotest.ErrReader(errors.New("connection reset")),
+							)), nil
+						}
+						return io.NopCloser(bytes.NewBufferString("world")), nil
+					}
+
+					_, inErr = command.Run(context.Background(), destDir, inRequest)
+					Ω(inErr).ShouldNot(HaveOccurred())
+
+					Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(2))
+					_, _, offset := githubClient.DownloadReleaseAssetArgsForCall(1)
+					Ω(offset).Should(Equal(int64(6)))
+
+					Ω(os.ReadFile(filepath.Join(destDir, "example.txt"))).Should(Equal([]byte("hello world")))
+					Ω(filepath.Join(destDir, "example.txt.download")).ShouldNot(BeAnExistingFile())
+				})
+
+				It("resumes a download that ended early", func() {
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, _ github.ReleaseAsset, offset int64) (io.ReadCloser, error) {
+						return io.NopCloser(bytes.NewBufferString("hello world"[offset:min(offset+3, 11)])), nil
+					}
+
+					_, inErr = command.Run(context.Background(), destDir, inRequest)
+					Ω(inErr).ShouldNot(HaveOccurred())
+
+					Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(4))
+					Ω(os.ReadFile(filepath.Join(destDir, "example.txt"))).Should(Equal([]byte("hello world")))
+				})
+
+				It("gives up after resuming too often", func() {
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, _ github.ReleaseAsset, offset int64) (io.ReadCloser, error) {
+						return io.NopCloser(iotest.ErrReader(errors.New("connection reset"))), nil
+					}
+
+					_, inErr = command.Run(context.Background(), destDir, inRequest)
+					Ω(inErr).Should(MatchError(ContainSubstring("failed to download asset 'example.txt': connection reset")))
+					Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(6))
+
+					Ω(filepath.Join(destDir, "example.txt")).ShouldNot(BeAnExistingFile())
+					Ω(filepath.Join(destDir, "example.txt.download")).ShouldNot(BeAnExistingFile())
+				})
+
+				It("does not resume when the asset is larger than expected", func() {
+					githubClient.DownloadReleaseAssetReturns(io.NopCloser(bytes.NewBufferString("hello world!")), nil)
+
+					_, inErr = command.Run(context.Background(), destDir, inRequest)
+					Ω(inErr).Should(MatchError(ContainSubstring("received 12 bytes, but the asset is 11 bytes")))
+					Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(1))
+				})
+			})
+
 			Context("when listing release assets fails", func() {
 				disaster := errors.New("nope")
 
 				BeforeEach(func() {
 					githubClient.ListReleaseAssetsReturns(nil, disaster)
-					inResponse, inErr = command.Run(destDir, inRequest)
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 				})
 
 				It("returns the error", func() {
 					Ω(inErr).Should(Equal(disaster))
 				})
 			})
+
+			Context("when a checksum file is given", func() {
+				var contents map[string]string
+
+				BeforeEach(func() {
+					contents = map[string]string{
+						"example.txt": "text",
+						"example.rtf": "rich text",
+					}
+
+					githubClient.DownloadReleaseAssetStub = func(_ context.Context, asset github.ReleaseAsset, _ int64) (io.ReadCloser, error) {
+						return io.NopCloser(bytes.NewBufferString(contents[*asset.Name])), nil
+					}
+
+					inRequest.Params = resource.InParams{
+						Globs:        []string{"*.txt", "*.rtf"},
+						ChecksumFile: "SHA256SUMS",
+					}
+				})
+
+				JustBeforeEach(func() {
+					assets := []*github.ReleaseAsset{}
+					for i, name := range []string{"example.txt", "example.rtf", "SHA256SUMS", "example.txt.sha256"} {
+						if _, found := contents[name]; found {
+							assets = append(assets, buildAsset(int64(i), name))
+						}
+					}
+					githubClient.ListReleaseAssetsReturns(assets, nil)
+
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+				})
+
+				Context("in GNU coreutils format", func() {
+					BeforeEach(func() {
+						contents["SHA256SUMS"] = sha256Hex("text") + "  example.txt\n" +
+							sha256Hex("rich text") + " *example.rtf\n"
+					})
+
+					It("succeeds", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+					})
+
+					It("does not download the checksum file unless it matches the globs", func() {
+						Ω(filepath.Join(destDir, "SHA256SUMS")).ShouldNot(BeAnExistingFile())
+						Ω(filepath.Join(destDir, "example.txt")).Should(BeAnExistingFile())
+					})
+				})
+
+				Context("in BSD format", func() {
+					BeforeEach(func() {
+						contents["SHA256SUMS"] = "SHA256 (example.txt) = " + sha256Hex("text") + "\n" +
+							"SHA256 (example.rtf) = " + sha256Hex("rich text") + "\n"
+					})
+
+					It("succeeds", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+					})
+				})
+
+				Context("as per-file checksum assets", func() {
+					BeforeEach(func() {
+						contents["example.txt.sha256"] = sha256Hex("text") + "\n"
+						inRequest.Params.Globs = []string{"*.txt"}
+						inRequest.Params.ChecksumFile = "*.sha256"
+					})
+
+					It("succeeds", func() {
+						Ω(inErr).ShouldNot(HaveOccurred())
+					})
+				})
+
+				Context("when an asset does not match its checksum", func() {
+					BeforeEach(func() {
+						contents["SHA256SUMS"] = sha256Hex("text") + "  example.txt\n" +
+							sha256Hex("tampered") + "  example.rtf\n"
+					})
+
+					It("returns an error", func() {
+						Ω(inErr).Should(MatchError(ContainSubstring("sha256 checksum mismatch for asset 'example.rtf'")))
+					})
+				})
+
+				Context("when an asset is missing from the checksum file", func() {
+					BeforeEach(func() {
+						contents["SHA256SUMS"] = sha256Hex("text") + "  example.txt\n"
+					})
+
+					It("returns an error", func() {
+						Ω(inErr).Should(MatchError("asset 'example.rtf' is missing from the checksum file"))
+					})
+				})
+
+				Context("when the checksum file is not in the release", func() {
+					It("returns an error", func() {
+						Ω(inErr).Should(MatchError("could not find checksum asset that matches 'SHA256SUMS'"))
+					})
+				})
+			})
+
+			Context("when GitHub reports a digest for an asset", func() {
+				BeforeEach(func() {
+					asset := buildAsset(0, "example.txt")
+					asset.Digest = github.String("sha256:" + sha256Hex("some-content"))
+					githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{asset}, nil)
+				})
+
+				It("verifies the downloaded asset against it", func() {
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+					Ω(inErr).ShouldNot(HaveOccurred())
+				})
+
+				It("fails if the downloaded asset does not match", func() {
+					githubClient.DownloadReleaseAssetReturns(io.NopCloser(bytes.NewBufferString("corrupted")), nil)
+
+					inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
+					Ω(inErr).Should(MatchError(ContainSubstring("sha256 checksum mismatch for asset 'example.txt'")))
+				})
+			})
 		})
 	})
 
@@ -472,7 +1492,7 @@ var _ = Describe("In Command", func() {
 				Tag: "v0.40.0",
 			}
 
-			inResponse, inErr = command.Run(destDir, inRequest)
+			inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 		})
 
 		It("returns an error", func() {
@@ -489,7 +1509,7 @@ var _ = Describe("In Command", func() {
 			inRequest.Version = &resource.Version{
 				Tag: "some-tag",
 			}
-			inResponse, inErr = command.Run(destDir, inRequest)
+			inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 		})
 
 		It("returns the error", func() {
@@ -503,7 +1523,7 @@ var _ = Describe("In Command", func() {
 				githubClient.GetReleaseReturns(buildRelease(1, "v0.35.0", true), nil)
 
 				inRequest.Version = &resource.Version{ID: "1"}
-				inResponse, inErr = command.Run(destDir, inRequest)
+				inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 			})
 
 			It("succeeds", func() {
@@ -544,7 +1564,7 @@ var _ = Describe("In Command", func() {
 				githubClient.GetReleaseReturns(buildRelease(1, "", true), nil)
 
 				inRequest.Version = &resource.Version{ID: "1"}
-				inResponse, inErr = command.Run(destDir, inRequest)
+				inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 			})
 
 			It("succeeds", func() {
@@ -583,7 +1603,7 @@ var _ = Describe("In Command", func() {
 				githubClient.GetReleaseReturns(buildNilTagRelease(1), nil)
 
 				inRequest.Version = &resource.Version{ID: "1"}
-				inResponse, inErr = command.Run(destDir, inRequest)
+				inResponse, inErr = command.Run(context.Background(), destDir, inRequest)
 			})
 
 			It("succeeds", func() {
@@ -617,3 +1637,142 @@ var _ = Describe("In Command", func() {
 		})
 	})
 })
+
+type archiveEntry struct {
+	name    string
+	content string
+	link    string
+	mode    int64
+}
+
+// tarArchive builds a tar archive of the entries, compressed with gzip, xz
+// or zstd, or not at all. Names ending in a slash are directories.
+func tarArchive(compression string, entries ...archiveEntry) []byte {
+	var buf bytes.Buffer
+
+	var w io.WriteCloser
+	var err error
+	switch compression {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "xz":
+		w, err = xz.NewWriter(&buf)
+	case "zstd":
+		w, err = zstd.NewWriter(&buf)
+	default:
+		w = nopWriteCloser{&buf}
+	}
+	Ω(err).ShouldNot(HaveOccurred())
+
+	tw := tar.NewWriter(w)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: entry.mode}
+		switch {
+		case strings.HasSuffix(entry.name, "/"):
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+		case entry.link != "":
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = entry.link
+		default:
+			header.Typeflag = tar.TypeReg
+			header.Size = int64(len(entry.content))
+			if header.Mode == 0 {
+				header.Mode = 0644
+			}
+		}
+
+		Ω(tw.WriteHeader(header)).Should(Succeed())
+		_, err := tw.Write([]byte(entry.content))
+		Ω(err).ShouldNot(HaveOccurred())
+	}
+
+	Ω(tw.Close()).Should(Succeed())
+	Ω(w.Close()).Should(Succeed())
+
+	return buf.Bytes()
+}
+
+func zipArchive(entries ...archiveEntry) []byte {
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		content := entry.content
+		if entry.link != "" {
+			header.SetMode(os.ModeSymlink | 0777)
+			content = entry.link
+		}
+
+		w, err := zw.CreateHeader(header)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = w.Write([]byte(content))
+		Ω(err).ShouldNot(HaveOccurred())
+	}
+
+	Ω(zw.Close()).Should(Succeed())
+
+	return buf.Bytes()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func gpgPublicKey(entity *openpgp.Entity) string {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(entity.Serialize(w)).Should(Succeed())
+	Ω(w.Close()).Should(Succeed())
+	return buf.String()
+}
+
+func gpgSignature(entity *openpgp.Entity, content string, armored bool) string {
+	var buf bytes.Buffer
+	if armored {
+		Ω(openpgp.ArmoredDetachSign(&buf, entity, strings.NewReader(content), nil)).Should(Succeed())
+	} else {
+		Ω(openpgp.DetachSign(&buf, entity, strings.NewReader(content), nil)).Should(Succeed())
+	}
+	return buf.String()
+}
+
+func cosignPublicKey(key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	Ω(err).ShouldNot(HaveOccurred())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func cosignSignature(key *ecdsa.PrivateKey, content string) []byte {
+	sum := sha256.Sum256([]byte(content))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	Ω(err).ShouldNot(HaveOccurred())
+	return signature
+}
+
+// sigstoreBundleFor builds a Sigstore bundle for content signed with a key,
+// like cosign sign-blob --key --new-bundle-format writes.
+func sigstoreBundleFor(key *ecdsa.PrivateKey, content string) string {
+	sum := sha256.Sum256([]byte(content))
+	bundle, err := json.Marshal(map[string]any{
+		"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"verificationMaterial": map[string]any{
+			"publicKey": map[string]any{"hint": "release-key"},
+		},
+		"messageSignature": map[string]any{
+			"messageDigest": map[string]any{
+				"algorithm": "SHA2_256",
+				"digest":    sum[:],
+			},
+			"signature": cosignSignature(key, content),
+		},
+	})
+	Ω(err).ShouldNot(HaveOccurred())
+	return string(bundle)
+}
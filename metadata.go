@@ -1,6 +1,14 @@
 package resource
 
-import "github.com/google/go-github/v66/github"
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"go.yaml.in/yaml/v3"
+)
 
 func metadataFromRelease(release *github.RepositoryRelease, commitSHA string) []MetadataPair {
 	metadata := []MetadataPair{}
@@ -62,3 +70,77 @@ func metadataFromRelease(release *github.RepositoryRelease, commitSHA string) []
 	}
 	return metadata
 }
+
+// releaseMetadata is the release as written to release.json and
+// release.yaml.
+type releaseMetadata struct {
+	ID              int64                `json:"id" yaml:"id"`
+	NodeID          string               `json:"node_id" yaml:"node_id"`
+	Name            string               `json:"name" yaml:"name"`
+	Tag             string               `json:"tag" yaml:"tag"`
+	TargetCommitish string               `json:"target_commitish" yaml:"target_commitish"`
+	CommitSHA       string               `json:"commit_sha" yaml:"commit_sha"`
+	Author          string               `json:"author" yaml:"author"`
+	Draft           bool                 `json:"draft" yaml:"draft"`
+	Prerelease      bool                 `json:"prerelease" yaml:"prerelease"`
+	Latest          *bool                `json:"latest" yaml:"latest"`
+	CreatedAt       *time.Time           `json:"created_at" yaml:"created_at"`
+	PublishedAt     *time.Time           `json:"published_at" yaml:"published_at"`
+	URL             string               `json:"url" yaml:"url"`
+	Body            string               `json:"body" yaml:"body"`
+	Assets          []assetManifestEntry `json:"assets" yaml:"assets"`
+	Version         *versionComponents   `json:"version" yaml:"version"`
+}
+
+func newReleaseMetadata(release *github.RepositoryRelease, commitSHA string, latest *bool, version *versionComponents) releaseMetadata {
+	metadata := releaseMetadata{
+		ID:              release.GetID(),
+		NodeID:          release.GetNodeID(),
+		Name:            release.GetName(),
+		Tag:             release.GetTagName(),
+		TargetCommitish: release.GetTargetCommitish(),
+		CommitSHA:       commitSHA,
+		Author:          release.GetAuthor().GetLogin(),
+		Draft:           release.GetDraft(),
+		Prerelease:      release.GetPrerelease(),
+		Latest:          latest,
+		URL:             release.GetHTMLURL(),
+		Body:            release.GetBody(),
+		Assets:          assetManifest(release.Assets),
+		Version:         version,
+	}
+
+	if release.CreatedAt != nil {
+		metadata.CreatedAt = &release.CreatedAt.Time
+	}
+	if release.PublishedAt != nil {
+		metadata.PublishedAt = &release.PublishedAt.Time
+	}
+
+	return metadata
+}
+
+// writeReleaseMetadata writes release.json into dir, and release.yaml as
+// well if asked to.
+func writeReleaseMetadata(dir string, metadata releaseMetadata, includeYAML bool) error {
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "release.json"), append(content, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+
+	if !includeYAML {
+		return nil
+	}
+
+	content, err = yaml.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, "release.yaml"), content, 0644)
+}
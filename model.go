@@ -1,6 +1,9 @@
 package resource
 
-import "github.com/shurcooL/githubv4"
+import (
+	"github.com/google/go-github/v74/github"
+	"github.com/shurcooL/githubv4"
+)
 
 // ReleaseObject represent the graphql release object
 // https://developer.github.com/v4/object/release
@@ -14,6 +17,10 @@ type ReleaseObject struct {
 	Name         string            `graphql:"name"`
 	TagName      string            `graphql:"tagName"`
 	URL          string            `graphql:"url"`
+
+	Description *string       `graphql:"description"`
+	Author      *AuthorObject `graphql:"author"`
+	TagCommit   *CommitObject `graphql:"tagCommit"`
 }
 
 // ReleaseObjectEnterprise Workaround until DatabaseId will appear in enterprise installation
@@ -27,4 +34,30 @@ type ReleaseObjectEnterprise struct {
 	Name         string            `graphql:"name"`
 	TagName      string            `graphql:"tagName"`
 	URL          string            `graphql:"url"`
+
+	Description *string       `graphql:"description"`
+	Author      *AuthorObject `graphql:"author"`
+	TagCommit   *CommitObject `graphql:"tagCommit"`
+}
+
+// AuthorObject is the user who created a release.
+// https://docs.github.com/en/graphql/reference/objects#user
+type AuthorObject struct {
+	Login string `graphql:"login"`
+}
+
+// author converts the author of a release, which is null for deleted users.
+func (a *AuthorObject) author() *github.User {
+	if a == nil {
+		return nil
+	}
+
+	return &github.User{Login: github.String(a.Login)}
+}
+
+// CommitObject is the commit a release's tag points to, which is null for
+// drafts whose tag does not exist yet.
+// https://docs.github.com/en/graphql/reference/objects#commit
+type CommitObject struct {
+	OID string `graphql:"oid"`
 }
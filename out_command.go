@@ -1,13 +1,16 @@
 package resource
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/google/go-github/v74/github"
 )
 
 type OutCommand struct {
@@ -18,11 +21,11 @@ type OutCommand struct {
 func NewOutCommand(github GitHub, writer io.Writer) *OutCommand {
 	return &OutCommand{
 		github: github,
-		writer: writer,
+		writer: &syncWriter{writer: writer},
 	}
 }
 
-func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, error) {
+func (c *OutCommand) Run(ctx context.Context, sourceDir string, request OutRequest) (OutResponse, error) {
 	params := request.Params
 
 	name, err := c.fileContents(filepath.Join(sourceDir, request.Params.NamePath))
@@ -64,6 +67,56 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 
 	generateReleaseNotes := request.Params.GenerateReleaseNotes
 
+	var manifests []checksumManifest
+	if len(params.Checksums) > 0 {
+		manifests, err = newChecksumManifests(params.ChecksumFormat, params.ChecksumFile, params.Checksums)
+		if err != nil {
+			return OutResponse{}, err
+		}
+	}
+
+	signer, err := newAssetSigner(params.Sign)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	syncAssets := false
+	switch params.AssetMode {
+	case "", "replace":
+	case "sync":
+		syncAssets = true
+	default:
+		return OutResponse{}, fmt.Errorf("unsupported asset_mode '%s'", params.AssetMode)
+	}
+
+	if params.DeleteUnmatchedAssets && !syncAssets {
+		return OutResponse{}, errors.New("delete_unmatched_assets requires asset_mode 'sync'")
+	}
+
+	var files []string
+	for _, fileGlob := range params.Globs {
+		matches, err := filepath.Glob(filepath.Join(sourceDir, fileGlob))
+		if err != nil {
+			return OutResponse{}, err
+		}
+
+		if len(matches) == 0 {
+			return OutResponse{}, fmt.Errorf("could not find file that matches glob '%s'", fileGlob)
+		}
+
+		files = append(files, matches...)
+	}
+
+	err = checkManifestConflicts(files, manifests)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	err = checkSignatureConflicts(signer, files, manifests)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
 	release := &github.RepositoryRelease{
 		Name:                 github.String(name),
 		TagName:              github.String(tag),
@@ -74,7 +127,7 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 		GenerateReleaseNotes: github.Bool(generateReleaseNotes),
 	}
 
-	existingReleases, err := c.github.ListReleases()
+	existingReleases, err := c.github.ListReleases(ctx)
 	if err != nil {
 		return OutResponse{}, err
 	}
@@ -87,8 +140,9 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 		}
 	}
 
+	var releaseAssets []*github.ReleaseAsset
 	if existingRelease != nil {
-		releaseAssets, err := c.github.ListReleaseAssets(*existingRelease)
+		releaseAssets, err = c.github.ListReleaseAssets(ctx, *existingRelease)
 		if err != nil {
 			return OutResponse{}, err
 		}
@@ -106,42 +160,70 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 		} else {
 			existingRelease.Body = nil
 		}
+	}
 
-		for _, asset := range releaseAssets {
-			fmt.Fprintf(c.writer, "clearing existing asset: %s\n", *asset.Name)
+	if params.DryRun {
+		return c.plan(release, existingRelease, releaseAssets, files, manifests, signer, syncAssets, params)
+	}
 
-			err := c.github.DeleteReleaseAsset(*asset)
-			if err != nil {
-				return OutResponse{}, err
+	var existingAssets map[string]*github.ReleaseAsset
+	if existingRelease != nil {
+		if syncAssets {
+			existingAssets = map[string]*github.ReleaseAsset{}
+			for _, asset := range releaseAssets {
+				existingAssets[*asset.Name] = asset
+			}
+		} else {
+			for _, asset := range releaseAssets {
+				fmt.Fprintf(c.writer, "clearing existing asset: %s\n", *asset.Name)
+
+				err := c.github.DeleteReleaseAsset(ctx, *asset)
+				if err != nil {
+					return OutResponse{}, err
+				}
 			}
 		}
 
 		fmt.Fprintf(c.writer, "updating release %s\n", name)
 
-		release, err = c.github.UpdateRelease(*existingRelease)
+		release, err = c.github.UpdateRelease(ctx, *existingRelease)
 		if err != nil {
 			return OutResponse{}, err
 		}
 	} else {
 		fmt.Fprintf(c.writer, "creating release %s\n", name)
-		release, err = c.github.CreateRelease(*release)
+		release, err = c.github.CreateRelease(ctx, *release)
 		if err != nil {
 			return OutResponse{}, err
 		}
 	}
 
-	for _, fileGlob := range params.Globs {
-		matches, err := filepath.Glob(filepath.Join(sourceDir, fileGlob))
+	uploaded, err := c.uploadFiles(ctx, release, files, existingAssets, params)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	err = c.uploadSignatures(ctx, release, signer, files, existingAssets)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	metadata := metadataFromRelease(release, "")
+
+	if len(manifests) > 0 {
+		err = c.uploadChecksumManifests(ctx, release, manifests, signer, uploaded, existingAssets)
 		if err != nil {
 			return OutResponse{}, err
 		}
 
-		if len(matches) == 0 {
-			return OutResponse{}, fmt.Errorf("could not find file that matches glob '%s'", fileGlob)
-		}
+		metadata = append(metadata, checksumMetadata(uploaded, params.Checksums)...)
+	}
+
+	if params.DeleteUnmatchedAssets {
+		for _, asset := range unmatchedAssets(releaseAssets, files, manifests, signer) {
+			fmt.Fprintf(c.writer, "deleting unmatched asset: %s\n", *asset.Name)
 
-		for _, filePath := range matches {
-			err := c.upload(release, filePath)
+			err := c.github.DeleteReleaseAsset(ctx, *asset)
 			if err != nil {
 				return OutResponse{}, err
 			}
@@ -150,10 +232,224 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 
 	return OutResponse{
 		Version:  versionFromRelease(release),
-		Metadata: metadataFromRelease(release, ""),
+		Metadata: metadata,
+	}, nil
+}
+
+// plan prints what a put would do and returns the response it would most
+// likely produce, while only reading from GitHub.
+func (c *OutCommand) plan(release, existingRelease *github.RepositoryRelease, releaseAssets []*github.ReleaseAsset, files []string, manifests []checksumManifest, signer *assetSigner, syncAssets bool, params OutParams) (OutResponse, error) {
+	fmt.Fprintln(c.writer, "dry run: no changes will be made")
+
+	version := Version{Tag: *release.TagName}
+	if existingRelease != nil {
+		release = existingRelease
+		version = versionFromRelease(existingRelease)
+		fmt.Fprintf(c.writer, "would update release %s\n", *release.Name)
+	} else {
+		fmt.Fprintf(c.writer, "would create release %s\n", *release.Name)
+	}
+
+	fmt.Fprintf(c.writer, "  tag: %s\n", *release.TagName)
+	if release.TargetCommitish != nil && *release.TargetCommitish != "" {
+		fmt.Fprintf(c.writer, "  commitish: %s\n", *release.TargetCommitish)
+	}
+	fmt.Fprintf(c.writer, "  draft: %t\n", *release.Draft)
+	fmt.Fprintf(c.writer, "  prerelease: %t\n", *release.Prerelease)
+
+	existing := map[string]*github.ReleaseAsset{}
+	for _, asset := range releaseAssets {
+		if syncAssets {
+			existing[*asset.Name] = asset
+		} else {
+			fmt.Fprintf(c.writer, "would clear existing asset: %s\n", *asset.Name)
+		}
+	}
+
+	var uploaded []assetDigests
+	for _, filePath := range files {
+		digests, err := c.planFile(filePath, existing[filepath.Base(filePath)], params.Checksums)
+		if err != nil {
+			return OutResponse{}, err
+		}
+
+		uploaded = append(uploaded, assetDigests{
+			name:    filepath.Base(filePath),
+			digests: digests,
+		})
+
+		c.planSignatures(signer, filepath.Base(filePath))
+	}
+
+	metadata := metadataFromRelease(release, "")
+
+	if len(manifests) > 0 {
+		tmpDir, err := os.MkdirTemp("", "github-release-checksums")
+		if err != nil {
+			return OutResponse{}, err
+		}
+		defer os.RemoveAll(tmpDir)
+
+		for _, manifest := range manifests {
+			manifestPath, err := writeChecksumManifest(tmpDir, manifest, uploaded)
+			if err != nil {
+				return OutResponse{}, err
+			}
+
+			_, err = c.planFile(manifestPath, existing[manifest.name], nil)
+			if err != nil {
+				return OutResponse{}, err
+			}
+
+			c.planSignatures(signer, manifest.name)
+		}
+
+		metadata = append(metadata, checksumMetadata(uploaded, params.Checksums)...)
+	}
+
+	if params.DeleteUnmatchedAssets {
+		for _, asset := range unmatchedAssets(releaseAssets, files, manifests, signer) {
+			fmt.Fprintf(c.writer, "would delete unmatched asset: %s\n", *asset.Name)
+		}
+	}
+
+	metadata = append(metadata, MetadataPair{Name: "dry_run", Value: "true"})
+
+	return OutResponse{
+		Version:  version,
+		Metadata: metadata,
 	}, nil
 }
 
+// planFile prints what syncFile would do with the file and returns its
+// digests for each of the algorithms.
+func (c *OutCommand) planFile(filePath string, existing *github.ReleaseAsset, algorithms []string) (map[string]string, error) {
+	name := filepath.Base(filePath)
+
+	if existing != nil {
+		digests, unchanged, err := assetMatchesFile(existing, filePath, algorithms)
+		if err != nil {
+			return nil, err
+		}
+
+		if unchanged {
+			fmt.Fprintf(c.writer, "unchanged asset: %s\n", name)
+			return digests, nil
+		}
+
+		fmt.Fprintf(c.writer, "would replace asset: %s\n", name)
+	} else {
+		fmt.Fprintf(c.writer, "would upload asset: %s\n", name)
+	}
+
+	return fileDigests(filePath, algorithms...)
+}
+
+// planSignatures prints the signatures the signer would upload for the
+// asset.
+func (c *OutCommand) planSignatures(signer *assetSigner, name string) {
+	if signer == nil {
+		return
+	}
+
+	for _, suffix := range signer.suffixes() {
+		fmt.Fprintf(c.writer, "would sign asset: %s as %s\n", name, name+suffix)
+	}
+}
+
+// uploadedNames returns the names of the assets a put uploads: the files,
+// the checksum manifests and the signatures of both.
+func uploadedNames(files []string, manifests []checksumManifest, signer *assetSigner) []string {
+	var names []string
+	for _, filePath := range files {
+		names = append(names, filepath.Base(filePath))
+	}
+	for _, manifest := range manifests {
+		names = append(names, manifest.name)
+	}
+
+	if signer != nil {
+		for _, name := range names {
+			for _, suffix := range signer.suffixes() {
+				names = append(names, name+suffix)
+			}
+		}
+	}
+
+	return names
+}
+
+// checkManifestConflicts fails if a checksum manifest would be uploaded under
+// the name of a file.
+func checkManifestConflicts(files []string, manifests []checksumManifest) error {
+	for _, manifest := range manifests {
+		for _, file := range files {
+			if filepath.Base(file) == manifest.name {
+				return fmt.Errorf("checksum manifest '%s' conflicts with an uploaded asset of the same name", manifest.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkSignatureConflicts fails if a signature would be uploaded under the
+// name of a file or checksum manifest.
+func checkSignatureConflicts(signer *assetSigner, files []string, manifests []checksumManifest) error {
+	if signer == nil {
+		return nil
+	}
+
+	names := uploadedNames(files, manifests, nil)
+
+	uploaded := map[string]bool{}
+	for _, name := range names {
+		uploaded[name] = true
+	}
+
+	for _, name := range names {
+		for _, suffix := range signer.suffixes() {
+			if uploaded[name+suffix] {
+				return fmt.Errorf("signature '%s' conflicts with an uploaded asset of the same name", name+suffix)
+			}
+		}
+	}
+
+	return nil
+}
+
+// unmatchedAssets returns the assets that neither a file, a checksum
+// manifest nor a signature would be uploaded as.
+func unmatchedAssets(assets []*github.ReleaseAsset, files []string, manifests []checksumManifest, signer *assetSigner) []*github.ReleaseAsset {
+	wanted := map[string]bool{}
+	for _, name := range uploadedNames(files, manifests, signer) {
+		wanted[name] = true
+	}
+
+	var unmatched []*github.ReleaseAsset
+	for _, asset := range assets {
+		if !wanted[*asset.Name] {
+			unmatched = append(unmatched, asset)
+		}
+	}
+
+	return unmatched
+}
+
+func checksumMetadata(uploaded []assetDigests, algorithms []string) []MetadataPair {
+	var metadata []MetadataPair
+	for _, asset := range uploaded {
+		for _, algorithm := range algorithms {
+			metadata = append(metadata, MetadataPair{
+				Name:  algorithm + ":" + asset.name,
+				Value: asset.digests[algorithm],
+			})
+		}
+	}
+
+	return metadata
+}
+
 func (c *OutCommand) fileContents(path string) (string, error) {
 	contents, err := os.ReadFile(path)
 	if err != nil {
@@ -163,35 +459,236 @@ func (c *OutCommand) fileContents(path string) (string, error) {
 	return strings.TrimSpace(string(contents)), nil
 }
 
-func (c *OutCommand) upload(release *github.RepositoryRelease, filePath string) error {
-	fmt.Fprintf(c.writer, "uploading %s\n", filePath)
+// uploadFiles uploads the files using up to upload_concurrency workers,
+// syncing them with the existing assets when there are any. All files are
+// attempted and the changed ones swapped in once every upload is done; if
+// any ultimately fail, their errors are returned together in the order of
+// the files.
+func (c *OutCommand) uploadFiles(ctx context.Context, release *github.RepositoryRelease, files []string, existing map[string]*github.ReleaseAsset, params OutParams) ([]assetDigests, error) {
+	concurrency := max(params.UploadConcurrency, 1)
+
+	uploaded := make([]assetDigests, len(files))
+	swaps := make([]*assetSwap, len(files))
+	errs := make([]error, len(files))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(files)) {
+		wg.Go(func() {
+			for i := range indexes {
+				digests, swap, err := c.syncFile(ctx, release, files[i], existing[filepath.Base(files[i])], params.Checksums)
+				if err != nil {
+					fmt.Fprintf(c.writer, "failed to upload %s: %s\n", files[i], err)
+					errs[i] = err
+					continue
+				}
 
+				uploaded[i] = assetDigests{
+					name:    filepath.Base(files[i]),
+					digests: digests,
+				}
+				swaps[i] = swap
+			}
+		})
+	}
+
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	var failures []error
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Errorf("failed to upload '%s': %w", filepath.Base(files[i]), err))
+		}
+	}
+
+	err := c.swapAssets(ctx, release, swaps)
+	if err != nil {
+		failures = append(failures, err)
+	}
+
+	if len(failures) > 0 {
+		return nil, errors.Join(failures...)
+	}
+
+	return uploaded, nil
+}
+
+// assetSwap is a changed file uploaded under a temporary name which has yet
+// to replace the existing asset.
+type assetSwap struct {
+	name     string
+	tmpName  string
+	existing *github.ReleaseAsset
+}
+
+// syncFile makes the release asset named after the file match it. Without an
+// existing asset the file is simply uploaded. An existing asset is left alone
+// if its size and digest match the file; otherwise the file is uploaded under
+// a temporary name and the returned swap has to be passed to swapAssets, so
+// that the existing asset stays in place for the duration of the upload.
+func (c *OutCommand) syncFile(ctx context.Context, release *github.RepositoryRelease, filePath string, existing *github.ReleaseAsset, algorithms []string) (map[string]string, *assetSwap, error) {
 	name := filepath.Base(filePath)
 
+	if existing == nil {
+		digests, err := c.upload(ctx, release, filePath, name, algorithms)
+		return digests, nil, err
+	}
+
+	digests, unchanged, err := assetMatchesFile(existing, filePath, algorithms)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if unchanged {
+		fmt.Fprintf(c.writer, "unchanged asset: %s\n", name)
+		return digests, nil, nil
+	}
+
+	tmpName := name + ".sync-tmp"
+
+	digests, err = c.upload(ctx, release, filePath, tmpName, algorithms)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return digests, &assetSwap{name: name, tmpName: tmpName, existing: existing}, nil
+}
+
+// swapAssets replaces existing assets with the files uploaded under
+// temporary names, listing the release's assets only once. GitHub can not
+// rename an asset over another, so each existing asset is deleted right
+// before its replacement is renamed and is briefly missing in between. If
+// the rename fails, the error names the temporary asset left in its place.
+func (c *OutCommand) swapAssets(ctx context.Context, release *github.RepositoryRelease, swaps []*assetSwap) error {
+	var pending []*assetSwap
+	for _, swap := range swaps {
+		if swap != nil {
+			pending = append(pending, swap)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	assets, err := c.github.ListReleaseAssets(ctx, *release)
+	if err != nil {
+		return err
+	}
+
+	uploaded := map[string]*github.ReleaseAsset{}
+	for _, asset := range assets {
+		if asset.Name != nil {
+			uploaded[*asset.Name] = asset
+		}
+	}
+
+	for _, swap := range pending {
+		replacement, found := uploaded[swap.tmpName]
+		if !found {
+			return fmt.Errorf("could not find uploaded asset '%s'", swap.tmpName)
+		}
+
+		err = c.github.DeleteReleaseAsset(ctx, *swap.existing)
+		if err != nil {
+			return fmt.Errorf("failed to delete asset '%s' to replace it with '%s': %w", swap.name, swap.tmpName, err)
+		}
+
+		replacement.Name = github.String(swap.name)
+		_, err = c.github.UpdateReleaseAsset(ctx, *replacement)
+		if err != nil {
+			return fmt.Errorf("failed to rename '%s' to '%s' after deleting the old asset, rename it by hand: %w", swap.tmpName, swap.name, err)
+		}
+
+		fmt.Fprintf(c.writer, "replaced asset: %s\n", swap.name)
+	}
+
+	return nil
+}
+
+// assetMatchesFile reports whether the asset was fully uploaded with the
+// size and digest of the file, along with the file's digests for each of the
+// algorithms. Assets without a digest can not be compared and never match.
+func assetMatchesFile(asset *github.ReleaseAsset, filePath string, algorithms []string) (map[string]string, bool, error) {
+	if asset.State == nil || *asset.State != "uploaded" || asset.Digest == nil {
+		return nil, false, nil
+	}
+
+	expected, ok := assetDigest(*asset.Digest)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if _, err := newHash(expected.algorithm); err != nil {
+		return nil, false, nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if asset.Size == nil || int64(*asset.Size) != info.Size() {
+		return nil, false, nil
+	}
+
+	digests, err := fileDigests(filePath, append([]string{expected.algorithm}, algorithms...)...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return digests, digests[expected.algorithm] == expected.digest, nil
+}
+
+// upload uploads the file as a release asset with the given name, returning
+// its digests for each of the algorithms as computed while the file was
+// streamed.
+func (c *OutCommand) upload(ctx context.Context, release *github.RepositoryRelease, filePath string, name string, algorithms []string) (map[string]string, error) {
+	fmt.Fprintf(c.writer, "uploading %s\n", filePath)
+
+	var digests map[string]string
 	var retryErr error
 	for range 10 {
 		file, err := os.Open(filePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		defer file.Close()
 
-		retryErr = c.github.UploadReleaseAsset(*release, name, file)
+		d, err := newDigester(algorithms...)
+		if err != nil {
+			return nil, err
+		}
+
+		retryErr = c.github.UploadReleaseAsset(ctx, *release, name, &digestingFile{file: file, digester: d})
 		if retryErr == nil {
+			// Cover anything the upload did not read so the digests always
+			// describe the whole file.
+			_, err = io.Copy(d, file)
+			if err != nil {
+				return nil, err
+			}
+
+			digests = d.digests()
 			break
 		}
 
-		assets, err := c.github.ListReleaseAssets(*release)
+		assets, err := c.github.ListReleaseAssets(ctx, *release)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		for _, asset := range assets {
 			if asset.Name != nil && *asset.Name == name {
-				err = c.github.DeleteReleaseAsset(*asset)
+				err = c.github.DeleteReleaseAsset(ctx, *asset)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				break
 			}
@@ -199,8 +696,113 @@ func (c *OutCommand) upload(release *github.RepositoryRelease, filePath string)
 	}
 
 	if retryErr != nil {
-		return retryErr
+		return nil, retryErr
 	}
 
-	return nil
+	fmt.Fprintf(c.writer, "uploaded %s\n", filePath)
+
+	return digests, nil
+}
+
+// uploadChecksumManifests renders the manifests over the uploaded assets and
+// uploads them alongside, syncing them like any other asset.
+func (c *OutCommand) uploadChecksumManifests(ctx context.Context, release *github.RepositoryRelease, manifests []checksumManifest, signer *assetSigner, uploaded []assetDigests, existing map[string]*github.ReleaseAsset) error {
+	tmpDir, err := os.MkdirTemp("", "github-release-checksums")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var manifestPaths []string
+	var swaps []*assetSwap
+	for _, manifest := range manifests {
+		manifestPath, err := writeChecksumManifest(tmpDir, manifest, uploaded)
+		if err != nil {
+			return err
+		}
+
+		_, swap, err := c.syncFile(ctx, release, manifestPath, existing[manifest.name], nil)
+		if err != nil {
+			return err
+		}
+
+		manifestPaths = append(manifestPaths, manifestPath)
+		swaps = append(swaps, swap)
+	}
+
+	err = c.swapAssets(ctx, release, swaps)
+	if err != nil {
+		return err
+	}
+
+	return c.uploadSignatures(ctx, release, signer, manifestPaths, existing)
+}
+
+// uploadSignatures signs the files and uploads the signatures alongside
+// them, syncing them like any other asset.
+func (c *OutCommand) uploadSignatures(ctx context.Context, release *github.RepositoryRelease, signer *assetSigner, files []string, existing map[string]*github.ReleaseAsset) error {
+	if signer == nil {
+		return nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "github-release-signatures")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var swaps []*assetSwap
+	for _, filePath := range files {
+		fmt.Fprintf(c.writer, "signing %s\n", filepath.Base(filePath))
+
+		signatures, err := signer.sign(filePath, tmpDir)
+		if err != nil {
+			return fmt.Errorf("failed to sign '%s': %w", filepath.Base(filePath), err)
+		}
+
+		for _, signaturePath := range signatures {
+			_, swap, err := c.syncFile(ctx, release, signaturePath, existing[filepath.Base(signaturePath)], nil)
+			if err != nil {
+				return err
+			}
+
+			swaps = append(swaps, swap)
+		}
+	}
+
+	return c.swapAssets(ctx, release, swaps)
+}
+
+// writeChecksumManifest renders the manifest over the uploaded assets into
+// the directory.
+func writeChecksumManifest(dir string, manifest checksumManifest, uploaded []assetDigests) (string, error) {
+	manifestPath := filepath.Join(dir, manifest.name)
+	err := os.WriteFile(manifestPath, manifest.render(uploaded), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return manifestPath, nil
+}
+
+// digestingFile feeds everything read from the file to a digester. It
+// deliberately does not embed *os.File so that readers cannot bypass Read
+// through io.WriterTo.
+type digestingFile struct {
+	file     *os.File
+	digester *digester
+}
+
+func (f *digestingFile) Read(p []byte) (int, error) {
+	n, err := f.file.Read(p)
+	f.digester.Write(p[:n])
+	return n, err
+}
+
+func (f *digestingFile) Name() string {
+	return f.file.Name()
+}
+
+func (f *digestingFile) Stat() (os.FileInfo, error) {
+	return f.file.Stat()
 }
@@ -1,18 +1,37 @@
 package resource_test
 
 import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 
 	resource "github.com/concourse/github-release-resource"
 	"github.com/concourse/github-release-resource/fakes"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
 )
 
 func file(path, contents string) {
@@ -38,7 +57,7 @@ var _ = Describe("Out Command", func() {
 		sourcesDir, err = os.MkdirTemp("", "github-release")
 		Ω(err).ShouldNot(HaveOccurred())
 
-		githubClient.CreateReleaseStub = func(gh github.RepositoryRelease) (*github.RepositoryRelease, error) {
+		githubClient.CreateReleaseStub = func(_ context.Context, gh github.RepositoryRelease) (*github.RepositoryRelease, error) {
 			createdRel := gh
 			createdRel.ID = github.Int64(112)
 			createdRel.HTMLURL = github.String("http://google.com")
@@ -47,7 +66,7 @@ var _ = Describe("Out Command", func() {
 			return &createdRel, nil
 		}
 
-		githubClient.UpdateReleaseStub = func(gh github.RepositoryRelease) (*github.RepositoryRelease, error) {
+		githubClient.UpdateReleaseStub = func(_ context.Context, gh github.RepositoryRelease) (*github.RepositoryRelease, error) {
 			return &gh, nil
 		}
 	})
@@ -83,7 +102,7 @@ var _ = Describe("Out Command", func() {
 		}
 
 		BeforeEach(func() {
-			githubClient.ListReleasesStub = func() ([]*github.RepositoryRelease, error) {
+			githubClient.ListReleasesStub = func(context.Context) ([]*github.RepositoryRelease, error) {
 				var rels []*github.RepositoryRelease
 				for _, r := range existingReleases {
 					c := r
@@ -93,7 +112,7 @@ var _ = Describe("Out Command", func() {
 				return rels, nil
 			}
 
-			githubClient.ListReleaseAssetsStub = func(github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+			githubClient.ListReleaseAssetsStub = func(context.Context, github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
 				var assets []*github.ReleaseAsset
 				for _, a := range existingAssets {
 					c := a
@@ -121,16 +140,19 @@ var _ = Describe("Out Command", func() {
 		})
 
 		It("deletes the existing assets", func() {
-			_, err := command.Run(sourcesDir, request)
+			_, err := command.Run(context.Background(), sourcesDir, request)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(1))
-			Ω(githubClient.ListReleaseAssetsArgsForCall(0)).Should(Equal(existingReleases[1]))
+			_, release := githubClient.ListReleaseAssetsArgsForCall(0)
+			Ω(release).Should(Equal(existingReleases[1]))
 
 			Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(2))
 
-			Ω(githubClient.DeleteReleaseAssetArgsForCall(0)).Should(Equal(existingAssets[0]))
-			Ω(githubClient.DeleteReleaseAssetArgsForCall(1)).Should(Equal(existingAssets[1]))
+			_, asset := githubClient.DeleteReleaseAssetArgsForCall(0)
+			Ω(asset).Should(Equal(existingAssets[0]))
+			_, asset = githubClient.DeleteReleaseAssetArgsForCall(1)
+			Ω(asset).Should(Equal(existingAssets[1]))
 		})
 
 		Context("when not set as a draft release", func() {
@@ -139,12 +161,12 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("updates the existing release to a non-draft", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				_, updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Draft).Should(Equal(false))
 			})
@@ -156,12 +178,12 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("updates the existing release to a draft", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				_, updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Draft).Should(Equal(true))
 			})
@@ -173,12 +195,12 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("does not blow away the body", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				_, updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(updatedRelease.Body).Should(BeNil())
 			})
@@ -186,12 +208,12 @@ var _ = Describe("Out Command", func() {
 
 		Context("when a commitish is not supplied", func() {
 			It("updates the existing release", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				_, updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Body).Should(Equal("this is a great release"))
 				Ω(updatedRelease.TargetCommitish).Should(BeNil(), "does not set the TargetCommitish")
@@ -206,12 +228,12 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("updates the existing release", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				_, updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Body).Should(Equal("this is a great release"))
 				Ω(updatedRelease.TargetCommitish).Should(Equal(github.String("1z22f1")))
@@ -224,17 +246,257 @@ var _ = Describe("Out Command", func() {
 			})
 			// See https://github.com/google/go-github/issues/2444
 			It("has no effect on updating the existing release", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				_, updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Body).Should(Equal("this is a great release"))
 				Ω(updatedRelease.GenerateReleaseNotes).Should(BeNil())
 			})
 		})
+
+		It("rejects an unknown asset_mode before updating the release", func() {
+			request.Params.AssetMode = "mirror"
+
+			_, err := command.Run(context.Background(), sourcesDir, request)
+			Ω(err).Should(MatchError("unsupported asset_mode 'mirror'"))
+			Ω(githubClient.UpdateReleaseCallCount()).Should(BeZero())
+		})
+
+		It("rejects delete_unmatched_assets without asset_mode sync", func() {
+			request.Params.DeleteUnmatchedAssets = true
+
+			_, err := command.Run(context.Background(), sourcesDir, request)
+			Ω(err).Should(MatchError("delete_unmatched_assets requires asset_mode 'sync'"))
+			Ω(githubClient.UpdateReleaseCallCount()).Should(BeZero())
+		})
+
+		Context("when dry_run is set", func() {
+			var output *gbytes.Buffer
+
+			BeforeEach(func() {
+				output = gbytes.NewBuffer()
+				command = resource.NewOutCommand(githubClient, output)
+
+				file(filepath.Join(sourcesDir, "great-file.tgz"), "matching")
+
+				request.Params.Globs = []string{"*.tgz"}
+				request.Params.DryRun = true
+			})
+
+			It("only reads from GitHub", func() {
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.ListReleasesCallCount()).Should(Equal(1))
+				Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(1))
+
+				Ω(githubClient.UpdateReleaseCallCount()).Should(BeZero())
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(BeZero())
+				Ω(githubClient.UploadReleaseAssetCallCount()).Should(BeZero())
+			})
+
+			It("prints the plan", func() {
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(output).Should(gbytes.Say("dry run: no changes will be made"))
+				Ω(output).Should(gbytes.Say("would update release v0.3.12"))
+				Ω(output).Should(gbytes.Say("would clear existing asset: unicorns.txt"))
+				Ω(output).Should(gbytes.Say("would clear existing asset: rainbows.txt"))
+				Ω(output).Should(gbytes.Say("would upload asset: great-file.tgz"))
+			})
+
+			It("responds with the existing version", func() {
+				outResponse, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(outResponse.Version.ID).Should(Equal("112"))
+				Ω(outResponse.Version.Tag).Should(Equal("some-tag-name"))
+				Ω(outResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "dry_run", Value: "true"}))
+			})
+
+			It("plans to sync and delete unmatched assets", func() {
+				request.Params.AssetMode = "sync"
+				request.Params.DeleteUnmatchedAssets = true
+				request.Params.Checksums = []string{"sha256"}
+
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(output).Should(gbytes.Say("would upload asset: great-file.tgz"))
+				Ω(output).Should(gbytes.Say("would upload asset: SHA256SUMS"))
+				Ω(output).Should(gbytes.Say("would delete unmatched asset: unicorns.txt"))
+				Ω(output).Should(gbytes.Say("would delete unmatched asset: rainbows.txt"))
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(BeZero())
+			})
+		})
+
+		Context("when asset_mode is sync", func() {
+			var assets []*github.ReleaseAsset
+
+			sha256Digest := func(content string) *string {
+				sum := sha256.Sum256([]byte(content))
+				return github.String("sha256:" + hex.EncodeToString(sum[:]))
+			}
+
+			uploadedNames := func() []string {
+				var names []string
+				for i := 0; i < githubClient.UploadReleaseAssetCallCount(); i++ {
+					_, _, name, _ := githubClient.UploadReleaseAssetArgsForCall(i)
+					names = append(names, name)
+				}
+				return names
+			}
+
+			BeforeEach(func() {
+				file(filepath.Join(sourcesDir, "added.txt"), "added")
+				file(filepath.Join(sourcesDir, "changed.txt"), "new content")
+				file(filepath.Join(sourcesDir, "same.txt"), "same")
+
+				assets = []*github.ReleaseAsset{
+					{
+						ID:     github.Int64(1),
+						Name:   github.String("same.txt"),
+						State:  github.String("uploaded"),
+						Size:   github.Int(4),
+						Digest: sha256Digest("same"),
+					},
+					{
+						ID:     github.Int64(2),
+						Name:   github.String("changed.txt"),
+						State:  github.String("uploaded"),
+						Size:   github.Int(11),
+						Digest: sha256Digest("old content"),
+					},
+					{
+						ID:    github.Int64(3),
+						Name:  github.String("stale.txt"),
+						State: github.String("uploaded"),
+					},
+				}
+
+				githubClient.ListReleaseAssetsStub = func(context.Context, github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+					var copies []*github.ReleaseAsset
+					for _, a := range assets {
+						c := *a
+						copies = append(copies, &c)
+					}
+
+					return copies, nil
+				}
+
+				githubClient.UploadReleaseAssetStub = func(_ context.Context, rel github.RepositoryRelease, name string, file resource.AssetFile) error {
+					assets = append(assets, &github.ReleaseAsset{
+						ID:    github.Int64(int64(100 + len(assets))),
+						Name:  github.String(name),
+						State: github.String("uploaded"),
+					})
+					return nil
+				}
+
+				request.Params.Globs = []string{"*.txt"}
+				request.Params.AssetMode = "sync"
+			})
+
+			It("only uploads new and changed files", func() {
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(uploadedNames()).Should(Equal([]string{"added.txt", "changed.txt.sync-tmp"}))
+			})
+
+			It("replaces changed assets once their replacement is uploaded", func() {
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(1))
+				_, asset := githubClient.DeleteReleaseAssetArgsForCall(0)
+				Ω(*asset.ID).Should(Equal(int64(2)))
+
+				Ω(githubClient.UpdateReleaseAssetCallCount()).Should(Equal(1))
+				_, renamed := githubClient.UpdateReleaseAssetArgsForCall(0)
+				Ω(*renamed.ID).Should(Equal(int64(104)))
+				Ω(*renamed.Name).Should(Equal("changed.txt"))
+			})
+
+			It("lists the assets once to replace every changed asset", func() {
+				assets[0].Digest = nil
+				listed := 0
+				githubClient.ListReleaseAssetsStub = func(context.Context, github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+					listed++
+					var copies []*github.ReleaseAsset
+					for _, a := range assets {
+						c := *a
+						copies = append(copies, &c)
+					}
+
+					return copies, nil
+				}
+
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.UpdateReleaseAssetCallCount()).Should(Equal(2))
+				Ω(listed).Should(Equal(2))
+			})
+
+			It("names the leftover temporary asset when the rename fails", func() {
+				githubClient.UpdateReleaseAssetReturns(nil, errors.New("nope"))
+
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).Should(MatchError("failed to rename 'changed.txt.sync-tmp' to 'changed.txt' after deleting the old asset, rename it by hand: nope"))
+			})
+
+			It("re-uploads assets without a digest to compare against", func() {
+				assets[0].Digest = nil
+
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(uploadedNames()).Should(ContainElement("same.txt.sync-tmp"))
+			})
+
+			It("includes the digests of unchanged assets in the metadata", func() {
+				request.Params.Checksums = []string{"sha256"}
+
+				outResponse, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				sum := sha256.Sum256([]byte("same"))
+				Ω(outResponse.Metadata).Should(ContainElement(
+					resource.MetadataPair{Name: "sha256:same.txt", Value: hex.EncodeToString(sum[:])},
+				))
+			})
+
+			It("keeps assets that no longer match any glob", func() {
+				_, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				for i := 0; i < githubClient.DeleteReleaseAssetCallCount(); i++ {
+					_, asset := githubClient.DeleteReleaseAssetArgsForCall(i)
+					Ω(*asset.Name).ShouldNot(Equal("stale.txt"))
+				}
+			})
+
+			Context("when delete_unmatched_assets is set", func() {
+				BeforeEach(func() {
+					request.Params.DeleteUnmatchedAssets = true
+				})
+
+				It("deletes assets that no longer match any glob", func() {
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(2))
+					_, asset := githubClient.DeleteReleaseAssetArgsForCall(1)
+					Ω(*asset.Name).Should(Equal("stale.txt"))
+				})
+			})
+		})
 	})
 
 	Context("when the release has not already been created", func() {
@@ -261,11 +523,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("creates a release on GitHub with the commitish", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(release.TargetCommitish).Should(Equal(github.String("a2f4a3")))
 			})
@@ -273,11 +535,11 @@ var _ = Describe("Out Command", func() {
 
 		Context("without a commitish", func() {
 			It("creates a release on GitHub without the commitish", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				// GitHub treats empty string the same as not suppying the field.
 				Ω(release.TargetCommitish).Should(Equal(github.String("")))
@@ -292,11 +554,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("creates a release on GitHub", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -306,11 +568,11 @@ var _ = Describe("Out Command", func() {
 
 		Context("without a body", func() {
 			It("works", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -319,11 +581,11 @@ var _ = Describe("Out Command", func() {
 		})
 
 		It("always defaults to non-draft mode", func() {
-			_, err := command.Run(sourcesDir, request)
+			_, err := command.Run(context.Background(), sourcesDir, request)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-			release := githubClient.CreateReleaseArgsForCall(0)
+			_, release := githubClient.CreateReleaseArgsForCall(0)
 
 			Ω(*release.Draft).Should(Equal(false))
 		})
@@ -337,11 +599,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("creates a non-draft pre-release in Github", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -351,7 +613,7 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("has some sweet metadata", func() {
-				outResponse, err := command.Run(sourcesDir, request)
+				outResponse, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(outResponse.Metadata).Should(ConsistOf(
@@ -373,11 +635,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("creates a final release in Github", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -387,7 +649,7 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("has some sweet metadata", func() {
-				outResponse, err := command.Run(sourcesDir, request)
+				outResponse, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(outResponse.Metadata).Should(ConsistOf(
@@ -407,11 +669,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("creates a release on GitHub in draft mode", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -421,7 +683,7 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("has some sweet metadata", func() {
-				outResponse, err := command.Run(sourcesDir, request)
+				outResponse, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(outResponse.Metadata).Should(ConsistOf(
@@ -460,11 +722,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("uploads matching file globs", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(1))
-				release, name, file := githubClient.UploadReleaseAssetArgsForCall(0)
+				_, release, name, file := githubClient.UploadReleaseAssetArgsForCall(0)
 
 				Ω(*release.ID).Should(Equal(int64(112)))
 				Ω(name).Should(Equal("great-file.tgz"))
@@ -472,7 +734,7 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("has some sweet metadata", func() {
-				outResponse, err := command.Run(sourcesDir, request)
+				outResponse, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(outResponse.Metadata).Should(ConsistOf(
@@ -489,7 +751,7 @@ var _ = Describe("Out Command", func() {
 					"*.gif",
 				}
 
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).Should(HaveOccurred())
 				Ω(err).Should(MatchError("could not find file that matches glob '*.gif'"))
 			})
@@ -497,7 +759,7 @@ var _ = Describe("Out Command", func() {
 			Context("when upload release asset fails", func() {
 				BeforeEach(func() {
 					existingAsset := false
-					githubClient.DeleteReleaseAssetStub = func(github.ReleaseAsset) error {
+					githubClient.DeleteReleaseAssetStub = func(context.Context, github.ReleaseAsset) error {
 						existingAsset = false
 						return nil
 					}
@@ -513,7 +775,7 @@ var _ = Describe("Out Command", func() {
 						},
 					}, nil)
 
-					githubClient.UploadReleaseAssetStub = func(rel github.RepositoryRelease, name string, file *os.File) error {
+					githubClient.UploadReleaseAssetStub = func(_ context.Context, rel github.RepositoryRelease, name string, file resource.AssetFile) error {
 						Expect(io.ReadAll(file)).To(Equal([]byte("matching")))
 						Expect(existingAsset).To(BeFalse())
 						existingAsset = true
@@ -522,20 +784,21 @@ var _ = Describe("Out Command", func() {
 				})
 
 				It("retries 10 times", func() {
-					_, err := command.Run(sourcesDir, request)
-					Expect(err).To(Equal(errors.New("some-error")))
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Expect(err).To(MatchError("failed to upload 'great-file.tgz': some-error"))
 
 					Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(10))
 					Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(10))
-					Ω(*githubClient.ListReleaseAssetsArgsForCall(9).ID).Should(Equal(int64(112)))
+					_, release := githubClient.ListReleaseAssetsArgsForCall(9)
+					Ω(*release.ID).Should(Equal(int64(112)))
 
-					actualRelease, actualName, actualFile := githubClient.UploadReleaseAssetArgsForCall(9)
+					_, actualRelease, actualName, actualFile := githubClient.UploadReleaseAssetArgsForCall(9)
 					Ω(*actualRelease.ID).Should(Equal(int64(112)))
 					Ω(actualName).Should(Equal("great-file.tgz"))
 					Ω(actualFile.Name()).Should(Equal(filepath.Join(sourcesDir, "great-file.tgz")))
 
 					Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(10))
-					actualAsset := githubClient.DeleteReleaseAssetArgsForCall(8)
+					_, actualAsset := githubClient.DeleteReleaseAssetArgsForCall(8)
 					Expect(*actualAsset.ID).To(Equal(int64(456789)))
 				})
 
@@ -549,30 +812,361 @@ var _ = Describe("Out Command", func() {
 						results <- nil
 						results <- errors.New("6")
 
-						githubClient.UploadReleaseAssetStub = func(github.RepositoryRelease, string, *os.File) error {
+						githubClient.UploadReleaseAssetStub = func(context.Context, github.RepositoryRelease, string, resource.AssetFile) error {
 							return <-results
 						}
 					})
 
 					It("succeeds", func() {
-						_, err := command.Run(sourcesDir, request)
+						_, err := command.Run(context.Background(), sourcesDir, request)
 						Expect(err).ToNot(HaveOccurred())
 
 						Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(5))
 						Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(4))
-						Ω(*githubClient.ListReleaseAssetsArgsForCall(3).ID).Should(Equal(int64(112)))
+						_, release := githubClient.ListReleaseAssetsArgsForCall(3)
+						Ω(*release.ID).Should(Equal(int64(112)))
 
-						actualRelease, actualName, actualFile := githubClient.UploadReleaseAssetArgsForCall(4)
+						_, actualRelease, actualName, actualFile := githubClient.UploadReleaseAssetArgsForCall(4)
 						Ω(*actualRelease.ID).Should(Equal(int64(112)))
 						Ω(actualName).Should(Equal("great-file.tgz"))
 						Ω(actualFile.Name()).Should(Equal(filepath.Join(sourcesDir, "great-file.tgz")))
 
 						Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(4))
-						actualAsset := githubClient.DeleteReleaseAssetArgsForCall(3)
+						_, actualAsset := githubClient.DeleteReleaseAssetArgsForCall(3)
 						Expect(*actualAsset.ID).To(Equal(int64(456789)))
 					})
 				})
 			})
+
+			Context("when upload_concurrency is set", func() {
+				BeforeEach(func() {
+					file(filepath.Join(sourcesDir, "other-file.tgz"), "other")
+					file(filepath.Join(sourcesDir, "third-file.tgz"), "third")
+
+					request.Params.UploadConcurrency = 3
+				})
+
+				It("uploads the files in parallel", func() {
+					started := make(chan string, 3)
+					release := make(chan struct{})
+
+					githubClient.UploadReleaseAssetStub = func(_ context.Context, rel github.RepositoryRelease, name string, file resource.AssetFile) error {
+						started <- name
+						<-release
+						return nil
+					}
+
+					done := make(chan error)
+					go func() {
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						done <- err
+					}()
+
+					for range 3 {
+						Eventually(started).Should(Receive())
+					}
+					close(release)
+
+					Eventually(done).Should(Receive(BeNil()))
+					Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(3))
+				})
+
+				It("retries each file and reports every file that ultimately failed", func() {
+					githubClient.UploadReleaseAssetStub = func(_ context.Context, rel github.RepositoryRelease, name string, file resource.AssetFile) error {
+						if name == "great-file.tgz" {
+							return nil
+						}
+						return errors.New("nope")
+					}
+
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).Should(MatchError(
+						"failed to upload 'other-file.tgz': nope\n" +
+							"failed to upload 'third-file.tgz': nope",
+					))
+
+					Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(21))
+				})
+			})
+
+			Context("when checksums are requested", func() {
+				var uploads map[string]string
+
+				sha256Hex := func(content string) string {
+					sum := sha256.Sum256([]byte(content))
+					return hex.EncodeToString(sum[:])
+				}
+
+				sha512Hex := func(content string) string {
+					sum := sha512.Sum512([]byte(content))
+					return hex.EncodeToString(sum[:])
+				}
+
+				BeforeEach(func() {
+					file(filepath.Join(sourcesDir, "other-file.tgz"), "other")
+
+					uploads = map[string]string{}
+					githubClient.UploadReleaseAssetStub = func(_ context.Context, rel github.RepositoryRelease, name string, file resource.AssetFile) error {
+						content, err := io.ReadAll(file)
+						Ω(err).ShouldNot(HaveOccurred())
+						uploads[name] = string(content)
+						return nil
+					}
+
+					request.Params.Checksums = []string{"sha256"}
+				})
+
+				It("uploads a GNU coreutils manifest after the assets", func() {
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(3))
+					_, _, name, _ := githubClient.UploadReleaseAssetArgsForCall(2)
+					Ω(name).Should(Equal("SHA256SUMS"))
+
+					Ω(uploads["SHA256SUMS"]).Should(Equal(
+						sha256Hex("matching") + "  great-file.tgz\n" +
+							sha256Hex("other") + "  other-file.tgz\n",
+					))
+				})
+
+				It("includes the digests in the metadata", func() {
+					outResponse, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(outResponse.Metadata).Should(ContainElements(
+						resource.MetadataPair{Name: "sha256:great-file.tgz", Value: sha256Hex("matching")},
+						resource.MetadataPair{Name: "sha256:other-file.tgz", Value: sha256Hex("other")},
+					))
+				})
+
+				It("computes the digests even if the upload does not read the whole file", func() {
+					githubClient.UploadReleaseAssetStub = func(_ context.Context, rel github.RepositoryRelease, name string, file resource.AssetFile) error {
+						uploads[name] = "not read"
+						return nil
+					}
+
+					outResponse, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(outResponse.Metadata).Should(ContainElement(
+						resource.MetadataPair{Name: "sha256:great-file.tgz", Value: sha256Hex("matching")},
+					))
+				})
+
+				Context("with several algorithms", func() {
+					BeforeEach(func() {
+						request.Params.Checksums = []string{"sha256", "sha512"}
+					})
+
+					It("uploads a GNU coreutils manifest per algorithm", func() {
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						Ω(err).ShouldNot(HaveOccurred())
+
+						Ω(uploads).Should(HaveKey("SHA256SUMS"))
+						Ω(uploads["SHA512SUMS"]).Should(Equal(
+							sha512Hex("matching") + "  great-file.tgz\n" +
+								sha512Hex("other") + "  other-file.tgz\n",
+						))
+					})
+
+					It("uploads a single BSD manifest when asked to", func() {
+						request.Params.ChecksumFormat = "bsd"
+						request.Params.ChecksumFile = "great-checksums"
+
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						Ω(err).ShouldNot(HaveOccurred())
+
+						Ω(uploads).Should(HaveLen(3))
+						Ω(uploads["great-checksums"]).Should(Equal(
+							"SHA256 (great-file.tgz) = " + sha256Hex("matching") + "\n" +
+								"SHA256 (other-file.tgz) = " + sha256Hex("other") + "\n" +
+								"SHA512 (great-file.tgz) = " + sha512Hex("matching") + "\n" +
+								"SHA512 (other-file.tgz) = " + sha512Hex("other") + "\n",
+						))
+					})
+
+					It("refuses to name several manifests the same", func() {
+						request.Params.ChecksumFile = "checksums.txt"
+
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						Ω(err).Should(MatchError("checksum_file can only be set when a single checksum manifest is generated"))
+						Ω(githubClient.CreateReleaseCallCount()).Should(BeZero())
+					})
+				})
+
+				It("uploads a goreleaser manifest when asked to", func() {
+					request.Params.ChecksumFormat = "goreleaser"
+
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(uploads["checksums.txt"]).Should(Equal(
+						sha256Hex("matching") + "  great-file.tgz\n" +
+							sha256Hex("other") + "  other-file.tgz\n",
+					))
+				})
+
+				It("refuses to upload a manifest over a file of the same name", func() {
+					file(filepath.Join(sourcesDir, "great-checksums.txt"), "checksums")
+					request.Params.Globs = []string{"*.tgz", "*.txt"}
+					request.Params.ChecksumFormat = "goreleaser"
+					request.Params.ChecksumFile = "great-checksums.txt"
+
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).Should(MatchError("checksum manifest 'great-checksums.txt' conflicts with an uploaded asset of the same name"))
+					Ω(githubClient.CreateReleaseCallCount()).Should(BeZero())
+					Ω(githubClient.UploadReleaseAssetCallCount()).Should(BeZero())
+				})
+
+				It("rejects unknown algorithms before creating the release", func() {
+					request.Params.Checksums = []string{"crc32"}
+
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).Should(MatchError("unsupported checksum algorithm 'crc32'"))
+					Ω(githubClient.CreateReleaseCallCount()).Should(BeZero())
+				})
+			})
+
+			Context("when sign is set", func() {
+				var (
+					uploads map[string]string
+					output  *gbytes.Buffer
+
+					gpgEntity *openpgp.Entity
+					cosignKey *ecdsa.PrivateKey
+				)
+
+				BeforeEach(func() {
+					output = gbytes.NewBuffer()
+					command = resource.NewOutCommand(githubClient, output)
+
+					uploads = map[string]string{}
+					githubClient.UploadReleaseAssetStub = func(_ context.Context, rel github.RepositoryRelease, name string, file resource.AssetFile) error {
+						content, err := io.ReadAll(file)
+						Ω(err).ShouldNot(HaveOccurred())
+						uploads[name] = string(content)
+						return nil
+					}
+
+					var err error
+					gpgEntity, err = openpgp.NewEntity("Release Bot", "", "bot@example.com", nil)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					cosignKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					request.Params.Sign = resource.SignParams{
+						GPGKey:    gpgPrivateKey(gpgEntity, ""),
+						CosignKey: cosignPrivateKey(cosignKey),
+					}
+				})
+
+				It("uploads a GPG and a cosign signature for each file", func() {
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(uploads).Should(HaveLen(3))
+
+					_, err = openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{gpgEntity}, strings.NewReader("matching"), strings.NewReader(uploads["great-file.tgz.asc"]), nil)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					signature, err := base64.StdEncoding.DecodeString(uploads["great-file.tgz.sig"])
+					Ω(err).ShouldNot(HaveOccurred())
+					sum := sha256.Sum256([]byte("matching"))
+					Ω(ecdsa.VerifyASN1(&cosignKey.PublicKey, sum[:], signature)).Should(BeTrue())
+
+					Ω(output).Should(gbytes.Say("signing great-file.tgz"))
+				})
+
+				Context("with checksums", func() {
+					BeforeEach(func() {
+						request.Params.Checksums = []string{"sha256"}
+						request.Params.Sign.CosignKey = ""
+					})
+
+					It("signs the checksum manifest too", func() {
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						Ω(err).ShouldNot(HaveOccurred())
+
+						Ω(uploads).Should(HaveKey("great-file.tgz.asc"))
+						Ω(uploads).Should(HaveKey("SHA256SUMS"))
+
+						_, err = openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{gpgEntity}, strings.NewReader(uploads["SHA256SUMS"]), strings.NewReader(uploads["SHA256SUMS.asc"]), nil)
+						Ω(err).ShouldNot(HaveOccurred())
+					})
+				})
+
+				Context("with encrypted keys", func() {
+					BeforeEach(func() {
+						request.Params.Sign = resource.SignParams{
+							GPGKey:         gpgPrivateKey(gpgEntity, "gpg-secret"),
+							GPGPassphrase:  "gpg-secret",
+							CosignKey:      cosignEncryptedPrivateKey(cosignKey, "cosign-secret"),
+							CosignPassword: "cosign-secret",
+						}
+					})
+
+					It("decrypts them", func() {
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						Ω(err).ShouldNot(HaveOccurred())
+
+						Ω(uploads).Should(HaveKey("great-file.tgz.asc"))
+						Ω(uploads).Should(HaveKey("great-file.tgz.sig"))
+					})
+
+					It("fails before creating the release without the GPG passphrase", func() {
+						request.Params.Sign.GPGPassphrase = ""
+
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						Ω(err).Should(MatchError("invalid gpg_key: the key is encrypted, but no gpg_passphrase is set"))
+						Ω(githubClient.CreateReleaseCallCount()).Should(BeZero())
+					})
+
+					It("fails before creating the release with the wrong cosign password", func() {
+						request.Params.Sign.CosignPassword = "wrong"
+
+						_, err := command.Run(context.Background(), sourcesDir, request)
+						Ω(err).Should(MatchError("invalid cosign_key: could not decrypt the key: wrong cosign_password?"))
+						Ω(githubClient.CreateReleaseCallCount()).Should(BeZero())
+					})
+				})
+
+				It("refuses to upload a signature over a file of the same name", func() {
+					file(filepath.Join(sourcesDir, "great-file.tgz.sig"), "signature")
+					request.Params.Globs = []string{"*.tgz", "*.sig"}
+
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).Should(MatchError("signature 'great-file.tgz.sig' conflicts with an uploaded asset of the same name"))
+					Ω(githubClient.CreateReleaseCallCount()).Should(BeZero())
+				})
+
+				It("plans the signatures in a dry run", func() {
+					request.Params.DryRun = true
+
+					_, err := command.Run(context.Background(), sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(output).Should(gbytes.Say("would upload asset: great-file.tgz"))
+					Ω(output).Should(gbytes.Say("would sign asset: great-file.tgz as great-file.tgz.asc"))
+					Ω(output).Should(gbytes.Say("would sign asset: great-file.tgz as great-file.tgz.sig"))
+					Ω(uploads).Should(BeEmpty())
+				})
+			})
+		})
+
+		Context("when dry_run is set", func() {
+			BeforeEach(func() {
+				request.Params.DryRun = true
+			})
+
+			It("does not create the release", func() {
+				outResponse, err := command.Run(context.Background(), sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.CreateReleaseCallCount()).Should(BeZero())
+				Ω(outResponse.Version).Should(Equal(resource.Version{Tag: "0.3.12"}))
+			})
 		})
 
 		Context("when the tag_prefix is set", func() {
@@ -593,11 +1187,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("appends the TagPrefix onto the TagName", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("version-0.3.12"))
@@ -610,11 +1204,11 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("creates a release on GitHub without autogenerated release notes", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(release.GenerateReleaseNotes).Should(Equal(github.Bool(false)))
 			})
@@ -626,14 +1220,79 @@ var _ = Describe("Out Command", func() {
 			})
 
 			It("creates a release on GitHub with autogenerated release notes", func() {
-				_, err := command.Run(sourcesDir, request)
+				_, err := command.Run(context.Background(), sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				_, release := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(release.GenerateReleaseNotes).Should(Equal(github.Bool(true)))
 			})
 		})
 	})
 })
+
+func gpgPrivateKey(entity *openpgp.Entity, passphrase string) string {
+	if passphrase != "" {
+		copied := *entity
+		entity = &copied
+
+		var serialized bytes.Buffer
+		Ω(entity.SerializePrivate(&serialized, nil)).Should(Succeed())
+		entities, err := openpgp.ReadKeyRing(&serialized)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		entity = entities[0]
+		Ω(entity.EncryptPrivateKeys([]byte(passphrase), nil)).Should(Succeed())
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(entity.SerializePrivateWithoutSigning(w, nil)).Should(Succeed())
+	Ω(w.Close()).Should(Succeed())
+	return buf.String()
+}
+
+func cosignPrivateKey(key crypto.PrivateKey) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	Ω(err).ShouldNot(HaveOccurred())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// cosignEncryptedPrivateKey encrypts the key like cosign generate-key-pair,
+// though with cheaper scrypt parameters.
+func cosignEncryptedPrivateKey(key crypto.PrivateKey, password string) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	salt := make([]byte, 32)
+	_, err = rand.Read(salt)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	var nonce [24]byte
+	_, err = rand.Read(nonce[:])
+	Ω(err).ShouldNot(HaveOccurred())
+
+	secret, err := scrypt.Key([]byte(password), salt, 1024, 8, 1, 32)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	var boxKey [32]byte
+	copy(boxKey[:], secret)
+
+	envelope, err := json.Marshal(map[string]any{
+		"kdf": map[string]any{
+			"name":   "scrypt",
+			"params": map[string]int{"N": 1024, "r": 8, "p": 1},
+			"salt":   salt,
+		},
+		"cipher": map[string]any{
+			"name":  "nacl/secretbox",
+			"nonce": nonce[:],
+		},
+		"ciphertext": secretbox.Seal(nil, der, &nonce, &boxKey),
+	})
+	Ω(err).ShouldNot(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: envelope}))
+}
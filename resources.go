@@ -21,9 +21,42 @@ type Source struct {
 	Insecure         bool   `json:"insecure"`
 	AssetDir         bool   `json:"asset_dir"`
 
+	CACerts    string `json:"ca_certs"`
+	ClientCert string `json:"client_cert"`
+	ClientKey  string `json:"client_key"`
+
+	ProxyURL      string   `json:"proxy_url"`
+	ProxyUsername string   `json:"proxy_username"`
+	ProxyPassword string   `json:"proxy_password"`
+	NoProxy       []string `json:"no_proxy"`
+
+	GitHubAppID             int64  `json:"github_app_id"`
+	GitHubAppInstallationID int64  `json:"github_app_installation_id"`
+	GitHubAppPrivateKey     string `json:"github_app_private_key"`
+
+	MaxRetries int    `json:"max_retries"`
+	MaxWait    string `json:"max_wait"`
+
+	ConnectTimeout  string `json:"connect_timeout"`
+	RequestTimeout  string `json:"request_timeout"`
+	TransferTimeout string `json:"transfer_timeout"`
+
 	TagFilter        string `json:"tag_filter"`
 	OrderBy          string `json:"order_by"`
 	SemverConstraint string `json:"semver_constraint"`
+	VersionScheme    string `json:"version_scheme"`
+	CalVerFormat     string `json:"calver_format"`
+
+	RequiredAssets []string `json:"required_assets"`
+	MinAge         string   `json:"min_age"`
+
+	TargetCommitish string `json:"target_commitish"`
+	ReachableFrom   string `json:"reachable_from"`
+
+	NameFilter   string   `json:"name_filter"`
+	BodyFilter   string   `json:"body_filter"`
+	BodyExcludes []string `json:"body_excludes"`
+	Authors      []string `json:"authors"`
 }
 
 type CheckRequest struct {
@@ -34,18 +67,21 @@ type CheckRequest struct {
 func NewCheckRequest() CheckRequest {
 	res := CheckRequest{}
 	res.Source.Release = true
+	res.Source.MaxRetries = defaultMaxRetries
 	return res
 }
 
 func NewOutRequest() OutRequest {
 	res := OutRequest{}
 	res.Source.Release = true
+	res.Source.MaxRetries = defaultMaxRetries
 	return res
 }
 
 func NewInRequest() InRequest {
 	res := InRequest{}
 	res.Source.Release = true
+	res.Source.MaxRetries = defaultMaxRetries
 	return res
 }
 
@@ -59,6 +95,36 @@ type InParams struct {
 	Globs                []string `json:"globs"`
 	IncludeSourceTarball bool     `json:"include_source_tarball"`
 	IncludeSourceZip     bool     `json:"include_source_zip"`
+	IncludeReleaseYAML   bool     `json:"include_release_yaml"`
+	IncludeLatest        bool     `json:"include_latest"`
+	ChecksumFile         string   `json:"checksum_file"`
+	DownloadConcurrency  int      `json:"download_concurrency"`
+	SkipDownload         bool     `json:"skip_download"`
+	AssetManifest        bool     `json:"asset_manifest"`
+
+	Extract []ExtractParams `json:"extract"`
+	Verify  VerifyParams    `json:"verify"`
+}
+
+// ExtractParams extracts the downloaded assets matching Glob, which may be
+// tar, tar.gz, tar.xz, tar.zst or zip archives.
+type ExtractParams struct {
+	Glob            string `json:"glob"`
+	Format          string `json:"format"`
+	Into            string `json:"into"`
+	StripComponents int    `json:"strip_components"`
+	DeleteArchive   bool   `json:"delete_archive"`
+}
+
+// VerifyParams requires the downloaded assets to be signed by one of the
+// keys, with detached signatures published as sibling assets.
+type VerifyParams struct {
+	GPGKeys    []string `json:"gpg_keys"`
+	CosignKeys []string `json:"cosign_keys"`
+}
+
+func (p VerifyParams) enabled() bool {
+	return len(p.GPGKeys) > 0 || len(p.CosignKeys) > 0
 }
 
 type InResponse struct {
@@ -80,6 +146,28 @@ type OutParams struct {
 	GenerateReleaseNotes bool   `json:"generate_release_notes"`
 
 	Globs []string `json:"globs"`
+
+	Checksums      []string `json:"checksums"`
+	ChecksumFormat string   `json:"checksum_format"`
+	ChecksumFile   string   `json:"checksum_file"`
+
+	UploadConcurrency int `json:"upload_concurrency"`
+
+	AssetMode             string `json:"asset_mode"`
+	DeleteUnmatchedAssets bool   `json:"delete_unmatched_assets"`
+
+	Sign SignParams `json:"sign"`
+
+	DryRun bool `json:"dry_run"`
+}
+
+// SignParams signs the uploaded files and checksum manifests with a GPG key,
+// a cosign key or both, uploading the detached signatures alongside them.
+type SignParams struct {
+	GPGKey         string `json:"gpg_key"`
+	GPGPassphrase  string `json:"gpg_passphrase"`
+	CosignKey      string `json:"cosign_key"`
+	CosignPassword string `json:"cosign_password"`
 }
 
 type OutResponse struct {
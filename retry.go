@@ -0,0 +1,275 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultMaxWait    = 5 * time.Minute
+
+	retryBaseDelay = time.Second
+
+	// GitHub asks clients to wait at least a minute after hitting a
+	// secondary rate limit that doesn't say how long to back off for.
+	secondaryRateLimitDelay = time.Minute
+)
+
+type idempotentKey struct{}
+
+// idempotentContext marks requests made with ctx as safe to retry even
+// though they are not made with an idempotent method, e.g. GraphQL queries.
+func idempotentContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// retryTransport retries requests that failed because of rate limiting or
+// transient server and network errors.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+
+	requestTimeout  time.Duration
+	transferTimeout time.Duration
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func newRetryTransport(base http.RoundTripper, source Source) (*retryTransport, error) {
+	maxWait, err := parseDuration("max_wait", source.MaxWait, defaultMaxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	requestTimeout, err := parseDuration("request_timeout", source.RequestTimeout, defaultRequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	transferTimeout, err := parseDuration("transfer_timeout", source.TransferTimeout, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &retryTransport{
+		base:            base,
+		maxRetries:      source.MaxRetries,
+		maxWait:         maxWait,
+		requestTimeout:  requestTimeout,
+		transferTimeout: transferTimeout,
+		now:             time.Now,
+		sleep:           sleepContext,
+	}, nil
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	idempotent := isIdempotent(req)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.attempt(req)
+		if !rewindable || attempt >= t.maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		wait, retry := t.retryAfter(resp, err, attempt, idempotent)
+		if !retry {
+			return resp, err
+		}
+
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = fmt.Sprintf("HTTP status %d", resp.StatusCode)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		Sayf("%s %s failed (%s), retrying in %s\n", req.Method, req.URL.Redacted(), reason, wait.Round(time.Millisecond))
+
+		err = t.sleep(req.Context(), wait)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt makes a single attempt at the request, bounded by request_timeout
+// or, for asset transfers, transfer_timeout. The timeout keeps running
+// until the body of the response has been read and closed.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	timeout := t.requestTimeout
+	if transfer, _ := req.Context().Value(transferKey{}).(bool); transfer {
+		timeout = t.transferTimeout
+	}
+
+	if timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if ctx.Err() == context.DeadlineExceeded && req.Context().Err() == nil {
+			return nil, fmt.Errorf("timed out after %s: %w", timeout, err)
+		}
+		return nil, err
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+func (t *retryTransport) retryAfter(resp *http.Response, err error, attempt int, idempotent bool) (time.Duration, bool) {
+	if err != nil {
+		return t.backoff(attempt), idempotent
+	}
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		// The request was rejected without being processed, so it is safe
+		// to retry regardless of its method.
+		wait, limited := t.rateLimitWait(resp, attempt)
+		if !limited || wait > t.maxWait {
+			return 0, false
+		}
+		return wait, true
+
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if !idempotent {
+			return 0, false
+		}
+		if wait, ok := t.retryAfterHeader(resp); ok {
+			return wait, wait <= t.maxWait
+		}
+		return t.backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+// rateLimitWait determines how long to wait before retrying a rate limited
+// response, preferring the server's own instructions.
+func (t *retryTransport) rateLimitWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if wait, ok := t.retryAfterHeader(resp); ok {
+		return wait, true
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err == nil {
+			return max(time.Unix(reset, 0).Sub(t.now()), 0), true
+		}
+		return t.backoff(attempt), true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(resp) {
+		return max(t.backoff(attempt), secondaryRateLimitDelay), true
+	}
+
+	return 0, false
+}
+
+func (t *retryTransport) retryAfterHeader(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(t.now()), 0), true
+	}
+
+	return 0, false
+}
+
+// backoff returns an exponentially growing delay with jitter, capped at
+// maxWait.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.maxWait
+	if attempt < 16 {
+		delay = min(retryBaseDelay<<attempt, t.maxWait)
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+
+	idempotent, _ := req.Context().Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// isSecondaryRateLimit inspects the body of a 403 response for GitHub's
+// secondary rate limit message, leaving the body readable for the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
+
+// cancelOnClose releases the timeout of a request once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
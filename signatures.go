@@ -0,0 +1,600 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// maxSignatureSize bounds the signature assets read into memory.
+const maxSignatureSize = 1 << 20
+
+// signatureSuffixes are the suffixes of the sibling assets signatures are
+// looked for in, e.g. file.tgz.asc for file.tgz.
+var signatureSuffixes = []string{".asc", ".sig", ".sigstore.json", ".sigstore", ".bundle"}
+
+// signatureVerifier verifies files against the detached signatures
+// published next to them in a release, using the keys of the verify param.
+// Verification is offline: keyless Sigstore certificates and transparency
+// log entries are not checked.
+type signatureVerifier struct {
+	github GitHub
+
+	gpgKeys    openpgp.EntityList
+	cosignKeys []crypto.PublicKey
+
+	// assets are the uploaded assets of the release by name.
+	assets map[string]*github.ReleaseAsset
+}
+
+func newSignatureVerifier(gh GitHub, params VerifyParams, assets []*github.ReleaseAsset) (*signatureVerifier, error) {
+	verifier := &signatureVerifier{
+		github: gh,
+		assets: map[string]*github.ReleaseAsset{},
+	}
+
+	for i, key := range params.GPGKeys {
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gpg key at index %d: %w", i, err)
+		}
+
+		verifier.gpgKeys = append(verifier.gpgKeys, entities...)
+	}
+
+	for i, key := range params.CosignKeys {
+		publicKey, err := parsePublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cosign key at index %d: %w", i, err)
+		}
+
+		verifier.cosignKeys = append(verifier.cosignKeys, publicKey)
+	}
+
+	for _, asset := range assets {
+		if asset.GetState() == "uploaded" {
+			verifier.assets[asset.GetName()] = asset
+		}
+	}
+
+	return verifier, nil
+}
+
+// isSignature reports whether the asset holds the signature of another
+// uploaded asset of the release, which need not be signed itself. Assets
+// that merely end in a signature suffix, e.g. repo.bundle, are not.
+func (v *signatureVerifier) isSignature(name string) bool {
+	for _, suffix := range signatureSuffixes {
+		signed, found := strings.CutSuffix(name, suffix)
+		if found && v.assets[signed] != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parsePublicKey parses a PEM encoded ECDSA, RSA or Ed25519 public key, as
+// written by cosign generate-key-pair.
+func parsePublicKey(key string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// verify checks every signature of the named file that can be checked with
+// the configured keys, returning a description of each. It fails if there
+// is none, or if any of them does not verify.
+func (v *signatureVerifier) verify(ctx context.Context, name string, open func() (io.ReadCloser, error)) ([]string, error) {
+	var verified []string
+	var candidates []string
+
+	for _, suffix := range signatureSuffixes {
+		signatureName := name + suffix
+		if !v.checks(suffix) {
+			continue
+		}
+
+		candidates = append(candidates, signatureName)
+
+		asset, found := v.assets[signatureName]
+		if !found {
+			continue
+		}
+
+		signature, err := v.download(ctx, asset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download signature '%s': %w", signatureName, err)
+		}
+
+		var description string
+		switch suffix {
+		case ".asc":
+			description, err = v.verifyGPG(signature, open)
+		case ".sig":
+			description, err = v.verifySig(signature, open)
+		default:
+			description, err = v.verifyBundle(signature, open)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature '%s' for asset '%s': %w", signatureName, name, err)
+		}
+
+		verified = append(verified, fmt.Sprintf("%s %s", signatureName, description))
+	}
+
+	if len(verified) == 0 {
+		return nil, fmt.Errorf("asset '%s' is not signed: found none of %s", name, strings.Join(candidates, ", "))
+	}
+
+	return verified, nil
+}
+
+// checks reports whether signatures with the suffix can be checked with the
+// configured keys.
+func (v *signatureVerifier) checks(suffix string) bool {
+	switch suffix {
+	case ".asc":
+		return len(v.gpgKeys) > 0
+	case ".sig":
+		return len(v.gpgKeys) > 0 || len(v.cosignKeys) > 0
+	default:
+		return len(v.cosignKeys) > 0
+	}
+}
+
+func (v *signatureVerifier) download(ctx context.Context, asset *github.ReleaseAsset) ([]byte, error) {
+	content, err := v.github.DownloadReleaseAsset(ctx, *asset, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	signature, err := io.ReadAll(io.LimitReader(content, maxSignatureSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signature) > maxSignatureSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxSignatureSize)
+	}
+
+	return signature, nil
+}
+
+// verifySig verifies a .sig asset, which is a binary or armored GPG
+// signature, or a base64 encoded cosign signature.
+func (v *signatureVerifier) verifySig(signature []byte, open func() (io.ReadCloser, error)) (string, error) {
+	var errs []error
+
+	if len(v.gpgKeys) > 0 {
+		description, err := v.verifyGPG(signature, open)
+		if err == nil {
+			return description, nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(v.cosignKeys) > 0 {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err == nil {
+			var description string
+			description, err = v.verifyCosign(raw, nil, open)
+			if err == nil {
+				return "cosign signature " + description, nil
+			}
+		}
+		errs = append(errs, err)
+	}
+
+	return "", errors.Join(errs...)
+}
+
+func (v *signatureVerifier) verifyGPG(signature []byte, open func() (io.ReadCloser, error)) (string, error) {
+	signed, err := open()
+	if err != nil {
+		return "", err
+	}
+	defer signed.Close()
+
+	var signer *openpgp.Entity
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN PGP")) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(v.gpgKeys, signed, bytes.NewReader(signature), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(v.gpgKeys, signed, bytes.NewReader(signature), nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("gpg: %w", err)
+	}
+
+	description := "gpg signature by " + strings.ToUpper(hex.EncodeToString(signer.PrimaryKey.Fingerprint))
+	if identity := signer.PrimaryIdentity(); identity != nil {
+		description += " (" + identity.Name + ")"
+	}
+
+	return description, nil
+}
+
+// sigstoreBundle is a Sigstore bundle, or the older bundle written by
+// cosign sign-blob --bundle, of which only the signature over the message
+// is used.
+type sigstoreBundle struct {
+	MessageSignature *struct {
+		MessageDigest struct {
+			Algorithm string `json:"algorithm"`
+			Digest    []byte `json:"digest"`
+		} `json:"messageDigest"`
+		Signature []byte `json:"signature"`
+	} `json:"messageSignature"`
+	DSSEEnvelope json.RawMessage `json:"dsseEnvelope"`
+
+	Base64Signature string `json:"base64Signature"`
+}
+
+func (v *signatureVerifier) verifyBundle(content []byte, open func() (io.ReadCloser, error)) (string, error) {
+	var bundle sigstoreBundle
+	err := json.Unmarshal(content, &bundle)
+	if err != nil {
+		return "", fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	var signature, digest []byte
+	switch {
+	case bundle.MessageSignature != nil:
+		if bundle.MessageSignature.MessageDigest.Algorithm != "SHA2_256" {
+			return "", fmt.Errorf("unsupported digest algorithm '%s'", bundle.MessageSignature.MessageDigest.Algorithm)
+		}
+		signature = bundle.MessageSignature.Signature
+		digest = bundle.MessageSignature.MessageDigest.Digest
+	case bundle.DSSEEnvelope != nil:
+		return "", errors.New("bundles with DSSE envelopes are not supported")
+	case bundle.Base64Signature != "":
+		signature, err = base64.StdEncoding.DecodeString(bundle.Base64Signature)
+		if err != nil {
+			return "", fmt.Errorf("invalid bundle: %w", err)
+		}
+	default:
+		return "", errors.New("invalid bundle: no signature found")
+	}
+
+	description, err := v.verifyCosign(signature, digest, open)
+	if err != nil {
+		return "", err
+	}
+
+	return "sigstore bundle " + description, nil
+}
+
+// verifyCosign verifies the signature against each of the cosign keys,
+// returning the fingerprint of the one that verified it. If digest is set,
+// it must be the SHA-256 digest of the file.
+func (v *signatureVerifier) verifyCosign(signature, digest []byte, open func() (io.ReadCloser, error)) (string, error) {
+	signed, err := open()
+	if err != nil {
+		return "", err
+	}
+
+	// Ed25519 signs the message itself rather than its digest, so only then
+	// is the whole file kept in memory.
+	var message bytes.Buffer
+	hash := sha256.New()
+	w := io.Writer(hash)
+	for _, key := range v.cosignKeys {
+		if _, ok := key.(ed25519.PublicKey); ok {
+			w = io.MultiWriter(hash, &message)
+		}
+	}
+
+	_, err = io.Copy(w, signed)
+	signed.Close()
+	if err != nil {
+		return "", err
+	}
+
+	sum := hash.Sum(nil)
+	if digest != nil && !bytes.Equal(digest, sum) {
+		return "", errors.New("digest does not match the asset")
+	}
+
+	for _, key := range v.cosignKeys {
+		var valid bool
+		switch key := key.(type) {
+		case *ecdsa.PublicKey:
+			valid = ecdsa.VerifyASN1(key, sum, signature)
+		case *rsa.PublicKey:
+			valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, sum, signature) == nil
+		case ed25519.PublicKey:
+			valid = ed25519.Verify(key, message.Bytes(), signature)
+		}
+
+		if valid {
+			return "by key " + keyFingerprint(key), nil
+		}
+	}
+
+	return "", errors.New("not signed by any of the cosign keys")
+}
+
+// keyFingerprint identifies a public key by the start of the SHA-256 digest
+// of its encoding.
+func keyFingerprint(key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "unknown"
+	}
+
+	sum := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
+
+// assetSigner signs the files put to a release with the keys of the sign
+// param, writing armored GPG signatures as <file>.asc and base64 encoded
+// cosign signatures as <file>.sig, which is where get verifies them from.
+type assetSigner struct {
+	gpgEntity *openpgp.Entity
+	cosignKey crypto.Signer
+}
+
+// newAssetSigner returns nil if no key is configured.
+func newAssetSigner(params SignParams) (*assetSigner, error) {
+	if params.GPGKey == "" && params.CosignKey == "" {
+		if params.GPGPassphrase != "" || params.CosignPassword != "" {
+			return nil, errors.New("gpg_passphrase and cosign_password require a key to sign with")
+		}
+		return nil, nil
+	}
+
+	signer := &assetSigner{}
+
+	if params.GPGKey != "" {
+		entity, err := parseGPGPrivateKey(params.GPGKey, params.GPGPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gpg_key: %w", err)
+		}
+		signer.gpgEntity = entity
+	}
+
+	if params.CosignKey != "" {
+		key, err := parsePrivateKey(params.CosignKey, params.CosignPassword)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cosign_key: %w", err)
+		}
+		signer.cosignKey = key
+	}
+
+	return signer, nil
+}
+
+func parseGPGPrivateKey(key, passphrase string) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+
+		if entity.PrivateKey.Encrypted && passphrase == "" {
+			return nil, errors.New("the key is encrypted, but no gpg_passphrase is set")
+		}
+
+		err := entity.DecryptPrivateKeys([]byte(passphrase))
+		if err != nil {
+			return nil, err
+		}
+
+		return entity, nil
+	}
+
+	return nil, errors.New("no private key found")
+}
+
+// parsePrivateKey parses a PEM encoded ECDSA, RSA or Ed25519 private key,
+// either unencrypted or encrypted by cosign generate-key-pair.
+func parsePrivateKey(key, password string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+
+	der := block.Bytes
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED COSIGN PRIVATE KEY":
+		der, err = decryptCosignKey(der, password)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err = x509.ParsePKCS8PrivateKey(der)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(der)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(der)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(der)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch parsed := parsed.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey, ed25519.PrivateKey:
+		return parsed.(crypto.Signer), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+}
+
+// encryptedCosignKey is the envelope cosign encrypts private keys in.
+type encryptedCosignKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func decryptCosignKey(content []byte, password string) ([]byte, error) {
+	var envelope encryptedCosignKey
+	err := json.Unmarshal(content, &envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	if envelope.KDF.Name != "scrypt" || envelope.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported encryption %s with %s", envelope.Cipher.Name, envelope.KDF.Name)
+	}
+
+	if len(envelope.Cipher.Nonce) != 24 {
+		return nil, errors.New("invalid nonce")
+	}
+
+	params := envelope.KDF.Params
+	secret, err := scrypt.Key([]byte(password), envelope.KDF.Salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	var boxKey [32]byte
+	copy(nonce[:], envelope.Cipher.Nonce)
+	copy(boxKey[:], secret)
+
+	der, ok := secretbox.Open(nil, envelope.Ciphertext, &nonce, &boxKey)
+	if !ok {
+		return nil, errors.New("could not decrypt the key: wrong cosign_password?")
+	}
+
+	return der, nil
+}
+
+// suffixes are the suffixes of the signature assets the signer writes.
+func (s *assetSigner) suffixes() []string {
+	var suffixes []string
+	if s.gpgEntity != nil {
+		suffixes = append(suffixes, ".asc")
+	}
+	if s.cosignKey != nil {
+		suffixes = append(suffixes, ".sig")
+	}
+
+	return suffixes
+}
+
+// sign writes the signatures of the file into dir, returning their paths.
+func (s *assetSigner) sign(filePath, dir string) ([]string, error) {
+	name := filepath.Base(filePath)
+
+	var paths []string
+	if s.gpgEntity != nil {
+		var signature bytes.Buffer
+		err := withFile(filePath, func(file io.Reader) error {
+			return openpgp.ArmoredDetachSign(&signature, s.gpgEntity, file, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(dir, name+".asc")
+		err = os.WriteFile(path, append(signature.Bytes(), '\n'), 0644)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	if s.cosignKey != nil {
+		signature, err := s.cosignSignature(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(dir, name+".sig")
+		err = os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(signature)), 0644)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+func (s *assetSigner) cosignSignature(filePath string) ([]byte, error) {
+	// Ed25519 signs the message itself rather than its digest.
+	if key, ok := s.cosignKey.(ed25519.PrivateKey); ok {
+		message, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.Sign(key, message), nil
+	}
+
+	hash := sha256.New()
+	err := withFile(filePath, func(file io.Reader) error {
+		_, err := io.Copy(hash, file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cosignKey.Sign(rand.Reader, hash.Sum(nil), crypto.SHA256)
+}
+
+func withFile(path string, f func(io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return f(file)
+}
@@ -0,0 +1,183 @@
+package resource
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const defaultRequestTimeout = 5 * time.Minute
+
+type transferKey struct{}
+
+// transferContext marks requests made with ctx as transferring the content
+// of an asset or archive, bounding them by transfer_timeout rather than
+// request_timeout.
+func transferContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, transferKey{}, true)
+}
+
+// newHTTPClient returns a client for requests that do not go through the
+// GitHub API client, retrying them and applying the source's timeouts.
+func newHTTPClient(source Source) (*http.Client, error) {
+	transport, err := newTransport(source)
+	if err != nil {
+		return nil, err
+	}
+
+	retryTransport, err := newRetryTransport(transport, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: retryTransport}, nil
+}
+
+// newTransport returns the transport all requests of the resource are made
+// with, verifying servers against ca_certs, presenting the client
+// certificate, going through proxy_url and giving up on connecting after
+// connect_timeout, if configured.
+func newTransport(source Source) (http.RoundTripper, error) {
+	config, err := tlsConfig(source)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := proxyFunc(source)
+	if err != nil {
+		return nil, err
+	}
+
+	connectTimeout, err := parseDuration("connect_timeout", source.ConnectTimeout, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if config == nil && proxy == nil && connectTimeout == 0 {
+		return http.DefaultTransport, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config != nil {
+		transport.TLSClientConfig = config
+	}
+	if proxy != nil {
+		transport.Proxy = proxy
+	}
+	if connectTimeout > 0 {
+		dialer := &net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = connectTimeout
+	}
+
+	return transport, nil
+}
+
+// proxyFunc returns the proxy selection for proxy_url, or nil if the
+// proxy is taken from the environment.
+func proxyFunc(source Source) (func(*http.Request) (*url.URL, error), error) {
+	if source.ProxyURL == "" {
+		if source.ProxyUsername != "" || source.ProxyPassword != "" || len(source.NoProxy) > 0 {
+			return nil, errors.New("proxy_username, proxy_password and no_proxy require proxy_url")
+		}
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(source.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy_url '%s': scheme must be http, https or socks5", source.ProxyURL)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy_url '%s': missing host", source.ProxyURL)
+	}
+
+	if source.ProxyUsername != "" || source.ProxyPassword != "" {
+		proxyURL.User = url.UserPassword(source.ProxyUsername, source.ProxyPassword)
+	}
+
+	config := httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    strings.Join(source.NoProxy, ","),
+	}
+	proxy := config.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// tlsConfig builds the TLS configuration for the source, or returns nil if
+// the defaults apply.
+func tlsConfig(source Source) (*tls.Config, error) {
+	if !source.Insecure && source.CACerts == "" && source.ClientCert == "" && source.ClientKey == "" {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: source.Insecure,
+	}
+
+	if source.CACerts != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM([]byte(source.CACerts)) {
+			return nil, errors.New("invalid ca_certs: no PEM encoded certificates found")
+		}
+
+		config.RootCAs = pool
+	}
+
+	if source.ClientCert != "" || source.ClientKey != "" {
+		if source.ClientCert == "" || source.ClientKey == "" {
+			return nil, errors.New("client_cert and client_key must be set together")
+		}
+
+		cert, err := tls.X509KeyPair([]byte(source.ClientCert), []byte(source.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// parseDuration parses the duration of the named source field, returning
+// def if it is not set.
+func parseDuration(name, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	if d < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", name)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,757 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	semisemantic "github.com/cppforlife/go-semi-semantic/version"
+)
+
+// versionScheme parses and orders the versions extracted from tags, and
+// evaluates semver_constraint against them.
+type versionScheme interface {
+	parse(version string) (schemeVersion, error)
+	constraint(constraint string) (versionConstraint, error)
+}
+
+type schemeVersion interface {
+	// compare returns -1, 0 or 1 if the version is less than, equal to or
+	// greater than other, which is of the same scheme.
+	compare(other schemeVersion) int
+
+	// String returns the canonical form of the version.
+	String() string
+}
+
+type versionConstraint interface {
+	check(version string) bool
+}
+
+func newVersionScheme(name, calverFormat string) (versionScheme, error) {
+	if calverFormat != "" && name != "calver" {
+		return nil, errors.New("calver_format requires the calver version_scheme")
+	}
+
+	switch name {
+	case "":
+		return defaultScheme{}, nil
+	case "semver":
+		return semverScheme{}, nil
+	case "semi-semantic":
+		return semiSemanticScheme{}, nil
+	case "calver":
+		return newCalverScheme(calverFormat)
+	case "pep440":
+		return pep440Scheme{}, nil
+	case "debian":
+		return debianScheme{}, nil
+	case "numeric":
+		return numericScheme{}, nil
+	default:
+		return nil, fmt.Errorf("unknown version_scheme '%s': must be one of semver, semi-semantic, calver, pep440, debian or numeric", name)
+	}
+}
+
+// defaultScheme orders versions as semi-semantic ones but evaluates
+// semver_constraint as semver, as the resource always has.
+type defaultScheme struct {
+	semiSemanticScheme
+}
+
+func (defaultScheme) constraint(constraint string) (versionConstraint, error) {
+	return semverScheme{}.constraint(constraint)
+}
+
+type semverScheme struct{}
+
+type semverVersion struct{ *semver.Version }
+
+func (semverScheme) parse(version string) (schemeVersion, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return semverVersion{v}, nil
+}
+
+func (semverScheme) constraint(constraint string) (versionConstraint, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	return semverConstraint{c}, nil
+}
+
+func (v semverVersion) compare(other schemeVersion) int {
+	return v.Compare(other.(semverVersion).Version)
+}
+
+type semverConstraint struct{ *semver.Constraints }
+
+func (c semverConstraint) check(version string) bool {
+	v, err := semver.NewVersion(version)
+	return err == nil && c.Check(v)
+}
+
+type semiSemanticScheme struct{}
+
+type semiSemanticVersion struct{ semisemantic.Version }
+
+func (semiSemanticScheme) parse(version string) (schemeVersion, error) {
+	v, err := semisemantic.NewVersionFromString(version)
+	if err != nil {
+		return nil, err
+	}
+	return semiSemanticVersion{v}, nil
+}
+
+func (s semiSemanticScheme) constraint(constraint string) (versionConstraint, error) {
+	return parseComparisons(s, constraint)
+}
+
+func (v semiSemanticVersion) compare(other schemeVersion) int {
+	return v.Compare(other.(semiSemanticVersion).Version)
+}
+
+// comparisons is a constraint of the form '>= 1.2, < 2 || 3.1', satisfied
+// by versions that satisfy every comparison of any of the alternatives.
+type comparisons struct {
+	scheme       versionScheme
+	alternatives [][]comparison
+}
+
+type comparison struct {
+	op      string
+	version schemeVersion
+}
+
+var comparisonRegexp = regexp.MustCompile(`^(==|!=|>=|<=|>|<|=)?\s*([^\s,]+)`)
+
+// parseComparisons parses a constraint for schemes that have no syntax of
+// their own, comparing versions with =, !=, >, >=, < and <=.
+func parseComparisons(scheme versionScheme, constraint string) (versionConstraint, error) {
+	c := comparisons{scheme: scheme}
+	for _, alternative := range strings.Split(constraint, "||") {
+		var all []comparison
+
+		rest := strings.TrimSpace(alternative)
+		for rest != "" {
+			matches := comparisonRegexp.FindStringSubmatch(rest)
+			if matches == nil {
+				return nil, fmt.Errorf("invalid constraint '%s'", constraint)
+			}
+
+			version, err := scheme.parse(matches[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid constraint '%s': %w", constraint, err)
+			}
+
+			all = append(all, comparison{op: matches[1], version: version})
+			rest = strings.TrimLeft(rest[len(matches[0]):], " \t,")
+		}
+
+		if len(all) == 0 {
+			return nil, fmt.Errorf("invalid constraint '%s': empty alternative", constraint)
+		}
+
+		c.alternatives = append(c.alternatives, all)
+	}
+
+	return c, nil
+}
+
+func (c comparisons) check(version string) bool {
+	v, err := c.scheme.parse(version)
+	if err != nil {
+		return false
+	}
+
+	for _, all := range c.alternatives {
+		satisfied := true
+		for _, comparison := range all {
+			satisfied = satisfied && comparison.satisfiedBy(v)
+		}
+
+		if satisfied {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c comparison) satisfiedBy(v schemeVersion) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "", "=", "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
+
+// calverScheme parses versions following a format of calver.org tokens,
+// e.g. YYYY.0M.0D or YY.0M.MICRO-MODIFIER, ordering them by each of their
+// numeric parts in turn. Versions with a modifier come before those
+// without one, like prereleases.
+type calverScheme struct {
+	format string
+	re     *regexp.Regexp
+	tokens []string
+}
+
+type calverVersion struct {
+	original string
+	parts    []int
+	modifier string
+}
+
+var calverTokens = map[string]string{
+	"YYYY":     `(\d{4})`,
+	"YY":       `([1-9]\d{0,2}|0)`,
+	"0Y":       `(\d{2,3})`,
+	"MM":       `(1[0-2]|[1-9])`,
+	"0M":       `(1[0-2]|0[1-9])`,
+	"WW":       `(5[0-3]|[1-4]\d|[1-9])`,
+	"0W":       `(5[0-3]|[1-4]\d|0[1-9])`,
+	"DD":       `(3[01]|[12]\d|[1-9])`,
+	"0D":       `(3[01]|[12]\d|0[1-9])`,
+	"MAJOR":    `(\d+)`,
+	"MINOR":    `(\d+)`,
+	"MICRO":    `(\d+)`,
+	"MODIFIER": `([0-9A-Za-z][0-9A-Za-z.\-_]*)`,
+}
+
+var calverTokenRegexp = regexp.MustCompile(`YYYY|YY|0Y|MM|0M|WW|0W|DD|0D|MAJOR|MINOR|MICRO|MODIFIER`)
+
+func newCalverScheme(format string) (versionScheme, error) {
+	if format == "" {
+		return nil, errors.New("the calver version_scheme requires calver_format")
+	}
+
+	var (
+		pattern strings.Builder
+		tokens  []string
+	)
+
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range calverTokenRegexp.FindAllStringIndex(format, -1) {
+		token := format[loc[0]:loc[1]]
+		if token == "MODIFIER" && loc[1] != len(format) {
+			return nil, fmt.Errorf("invalid calver_format '%s': MODIFIER must come last", format)
+		}
+
+		// The modifier is optional, along with what separates it.
+		if token == "MODIFIER" {
+			pattern.WriteString("(?:" + regexp.QuoteMeta(format[last:loc[0]]) + calverTokens[token] + ")?")
+		} else {
+			pattern.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+			pattern.WriteString(calverTokens[token])
+		}
+		tokens = append(tokens, token)
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(format[last:]))
+	pattern.WriteString("$")
+
+	if len(tokens) == 0 || tokens[0] == "MODIFIER" {
+		return nil, fmt.Errorf("invalid calver_format '%s': no date or number tokens", format)
+	}
+
+	return calverScheme{
+		format: format,
+		re:     regexp.MustCompile(pattern.String()),
+		tokens: tokens,
+	}, nil
+}
+
+func (s calverScheme) parse(version string) (schemeVersion, error) {
+	matches := s.re.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("version '%s' does not match calver_format '%s'", version, s.format)
+	}
+
+	v := calverVersion{original: version}
+	for i, token := range s.tokens {
+		if token == "MODIFIER" {
+			v.modifier = matches[i+1]
+			continue
+		}
+
+		n, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid calver version '%s': %w", version, err)
+		}
+		v.parts = append(v.parts, n)
+	}
+
+	return v, nil
+}
+
+func (s calverScheme) constraint(constraint string) (versionConstraint, error) {
+	return parseComparisons(s, constraint)
+}
+
+func (v calverVersion) compare(other schemeVersion) int {
+	o := other.(calverVersion)
+	for i := range v.parts {
+		if c := compareInts(v.parts[i], o.parts[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case v.modifier == o.modifier:
+		return 0
+	case v.modifier == "":
+		return 1
+	case o.modifier == "":
+		return -1
+	default:
+		return compareAlphanumeric(v.modifier, o.modifier)
+	}
+}
+
+func (v calverVersion) String() string {
+	return v.original
+}
+
+// pep440Scheme parses Python package versions, as specified by PEP 440,
+// e.g. 1.0, 2!1.0.post1, 1.1rc1 or 1.2.dev3+local.1.
+type pep440Scheme struct{}
+
+type pep440Version struct {
+	epoch   int
+	release []int
+	pre     *pep440Segment
+	post    *int
+	dev     *int
+	local   []string
+}
+
+type pep440Segment struct {
+	label string
+	n     int
+}
+
+var pep440Regexp = regexp.MustCompile(`^v?` +
+	`(?:(\d+)!)?` +
+	`(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(alpha|a|beta|b|preview|pre|c|rc)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+var pep440PreLabels = map[string]string{
+	"alpha":   "a",
+	"a":       "a",
+	"beta":    "b",
+	"b":       "b",
+	"preview": "rc",
+	"pre":     "rc",
+	"c":       "rc",
+	"rc":      "rc",
+}
+
+func (pep440Scheme) parse(version string) (schemeVersion, error) {
+	matches := pep440Regexp.FindStringSubmatch(strings.ToLower(strings.TrimSpace(version)))
+	if matches == nil {
+		return nil, fmt.Errorf("invalid PEP 440 version '%s'", version)
+	}
+
+	atoi := func(s string) int {
+		// The regexp only matches digits, so the only failure is overflow.
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	v := pep440Version{epoch: atoi(matches[1])}
+	for _, part := range strings.Split(matches[2], ".") {
+		v.release = append(v.release, atoi(part))
+	}
+
+	if matches[3] != "" {
+		v.pre = &pep440Segment{label: pep440PreLabels[matches[3]], n: atoi(matches[4])}
+	}
+
+	if matches[5] != "" || matches[6] != "" {
+		post := atoi(matches[5] + matches[7])
+		v.post = &post
+	}
+
+	if matches[8] != "" {
+		dev := atoi(matches[9])
+		v.dev = &dev
+	}
+
+	if matches[10] != "" {
+		v.local = strings.FieldsFunc(matches[10], func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+
+	return v, nil
+}
+
+func (s pep440Scheme) constraint(constraint string) (versionConstraint, error) {
+	return parseComparisons(s, constraint)
+}
+
+func (v pep440Version) compare(other schemeVersion) int {
+	o := other.(pep440Version)
+
+	if c := compareInts(v.epoch, o.epoch); c != 0 {
+		return c
+	}
+
+	// Trailing zeros are insignificant, so 1.0 equals 1.0.0.
+	for i := range max(len(v.release), len(o.release)) {
+		var a, b int
+		if i < len(v.release) {
+			a = v.release[i]
+		}
+		if i < len(o.release) {
+			b = o.release[i]
+		}
+		if c := compareInts(a, b); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInts(v.preRank(), o.preRank()); c != 0 {
+		return c
+	}
+	if v.pre != nil && o.pre != nil {
+		if c := strings.Compare(v.pre.label, o.pre.label); c != 0 {
+			return c
+		}
+		if c := compareInts(v.pre.n, o.pre.n); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareOptionalInts(v.post, o.post, -1); c != 0 {
+		return c
+	}
+
+	if c := compareOptionalInts(v.dev, o.dev, 1); c != 0 {
+		return c
+	}
+
+	return compareLocal(v.local, o.local)
+}
+
+// preRank orders a development release without a pre-release segment,
+// e.g. 1.0.dev1, before pre-releases, and those before the final release.
+func (v pep440Version) preRank() int {
+	switch {
+	case v.pre == nil && v.post == nil && v.dev != nil:
+		return -1
+	case v.pre == nil:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (v pep440Version) String() string {
+	var b strings.Builder
+	if v.epoch != 0 {
+		fmt.Fprintf(&b, "%d!", v.epoch)
+	}
+
+	for i, part := range v.release {
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(strconv.Itoa(part))
+	}
+
+	if v.pre != nil {
+		fmt.Fprintf(&b, "%s%d", v.pre.label, v.pre.n)
+	}
+	if v.post != nil {
+		fmt.Fprintf(&b, ".post%d", *v.post)
+	}
+	if v.dev != nil {
+		fmt.Fprintf(&b, ".dev%d", *v.dev)
+	}
+	if len(v.local) > 0 {
+		b.WriteString("+" + strings.Join(v.local, "."))
+	}
+
+	return b.String()
+}
+
+// compareOptionalInts compares two segments that may be absent, ordering
+// an absent segment before present ones if missing is -1, or after them if
+// it is 1.
+func compareOptionalInts(a, b *int, missing int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return missing
+	case b == nil:
+		return -missing
+	default:
+		return compareInts(*a, *b)
+	}
+}
+
+// compareLocal compares local version labels, where numeric segments come
+// after alphanumeric ones and a longer label after its prefix.
+func compareLocal(a, b []string) int {
+	for i := range min(len(a), len(b)) {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareInts(an, bn)
+		case aErr == nil:
+			c = 1
+		case bErr == nil:
+			c = -1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+// debianScheme parses Debian package versions of the form
+// [epoch:]upstream_version[-debian_revision], ordered like dpkg does.
+type debianScheme struct{}
+
+type debianVersion struct {
+	original string
+	epoch    int
+	upstream string
+	revision string
+}
+
+var (
+	debianUpstreamRegexp = regexp.MustCompile(`^[0-9][A-Za-z0-9.+~-]*$`)
+	debianRevisionRegexp = regexp.MustCompile(`^[A-Za-z0-9.+~]+$`)
+)
+
+func (debianScheme) parse(version string) (schemeVersion, error) {
+	v := debianVersion{original: version}
+
+	rest := version
+	if epoch, after, found := strings.Cut(rest, ":"); found {
+		n, err := strconv.Atoi(epoch)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid Debian version '%s': invalid epoch '%s'", version, epoch)
+		}
+		v.epoch = n
+		rest = after
+	}
+
+	if i := strings.LastIndex(rest, "-"); i >= 0 {
+		v.revision = rest[i+1:]
+		rest = rest[:i]
+
+		if !debianRevisionRegexp.MatchString(v.revision) {
+			return nil, fmt.Errorf("invalid Debian version '%s': invalid revision '%s'", version, v.revision)
+		}
+	}
+
+	if !debianUpstreamRegexp.MatchString(rest) {
+		return nil, fmt.Errorf("invalid Debian version '%s': invalid upstream version '%s'", version, rest)
+	}
+	v.upstream = rest
+
+	return v, nil
+}
+
+func (s debianScheme) constraint(constraint string) (versionConstraint, error) {
+	return parseComparisons(s, constraint)
+}
+
+func (v debianVersion) compare(other schemeVersion) int {
+	o := other.(debianVersion)
+
+	if c := compareInts(v.epoch, o.epoch); c != 0 {
+		return c
+	}
+
+	if c := compareDebianPart(v.upstream, o.upstream); c != 0 {
+		return c
+	}
+
+	return compareDebianPart(v.revision, o.revision)
+}
+
+func (v debianVersion) String() string {
+	return v.original
+}
+
+// compareDebianPart compares upstream versions or revisions the way dpkg
+// does: alternating runs of non-digits, compared character by character
+// with ~ before everything and letters before other characters, and runs
+// of digits, compared numerically.
+func compareDebianPart(a, b string) int {
+	for a != "" || b != "" {
+		var aText, bText string
+		aText, a = splitRun(a, false)
+		bText, b = splitRun(b, false)
+
+		for i := range max(len(aText), len(bText)) {
+			if c := compareInts(debianOrder(aText, i), debianOrder(bText, i)); c != 0 {
+				return c
+			}
+		}
+
+		var aDigits, bDigits string
+		aDigits, a = splitRun(a, true)
+		bDigits, b = splitRun(b, true)
+
+		if c := compareDigits(aDigits, bDigits); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// debianOrder weighs the character at i of a run of non-digits, or the end
+// of the run if i is past it.
+func debianOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+
+	switch c := s[i]; {
+	case c == '~':
+		return -1
+	case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// numericScheme parses versions made of numbers only, e.g. build numbers
+// like 1234 or dotted ones like 10.2.3, ordered by each number in turn.
+type numericScheme struct{}
+
+type numericVersion []string
+
+var numericRegexp = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+func (numericScheme) parse(version string) (schemeVersion, error) {
+	if !numericRegexp.MatchString(version) {
+		return nil, fmt.Errorf("invalid numeric version '%s'", version)
+	}
+
+	return numericVersion(strings.Split(version, ".")), nil
+}
+
+func (s numericScheme) constraint(constraint string) (versionConstraint, error) {
+	return parseComparisons(s, constraint)
+}
+
+// compare orders the numbers of the versions in turn, with missing ones
+// counting as zero, so that 1.2 equals 1.2.0.
+func (v numericVersion) compare(other schemeVersion) int {
+	o := other.(numericVersion)
+	for i := range max(len(v), len(o)) {
+		a, b := "0", "0"
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(o) {
+			b = o[i]
+		}
+		if c := compareDigits(a, b); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func (v numericVersion) String() string {
+	return strings.Join(v, ".")
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareDigits compares runs of digits numerically, however long they
+// are. An empty run counts as zero.
+func compareDigits(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+
+	if c := compareInts(len(a), len(b)); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
+
+// compareAlphanumeric compares strings by their runs of digits numerically
+// and their other runs lexically, so that build10 comes after build9.
+func compareAlphanumeric(a, b string) int {
+	for a != "" || b != "" {
+		var aText, bText string
+		aText, a = splitRun(a, false)
+		bText, b = splitRun(b, false)
+
+		if c := strings.Compare(aText, bText); c != 0 {
+			return c
+		}
+
+		var aDigits, bDigits string
+		aDigits, a = splitRun(a, true)
+		bDigits, b = splitRun(b, true)
+
+		if c := compareDigits(aDigits, bDigits); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// splitRun splits off the leading run of digits, or of non-digits, of s.
+func splitRun(s string, digits bool) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r >= '0' && r <= '9') != digits
+	})
+	if i < 0 {
+		return s, ""
+	}
+
+	return s[:i], s[i:]
+}
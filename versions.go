@@ -1,20 +1,24 @@
 package resource
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"time"
 
-	"github.com/google/go-github/v66/github"
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-github/v74/github"
 )
 
 var defaultTagFilter = "^v?([^v].*)"
 
 type versionParser struct {
-	re *regexp.Regexp
+	re     *regexp.Regexp
+	scheme versionScheme
 }
 
-func newVersionParser(filter string) (versionParser, error) {
+func newVersionParser(source Source) (versionParser, error) {
+	filter := source.TagFilter
 	if filter == "" {
 		filter = defaultTagFilter
 	}
@@ -22,7 +26,11 @@ func newVersionParser(filter string) (versionParser, error) {
 	if err != nil {
 		return versionParser{}, err
 	}
-	return versionParser{re: re}, nil
+	scheme, err := newVersionScheme(source.VersionScheme, source.CalVerFormat)
+	if err != nil {
+		return versionParser{}, err
+	}
+	return versionParser{re: re, scheme: scheme}, nil
 }
 
 func (vp *versionParser) parse(tag string) string {
@@ -33,6 +41,102 @@ func (vp *versionParser) parse(tag string) string {
 	return ""
 }
 
+// version parses the version extracted from the tag with the version
+// scheme of the source.
+func (vp *versionParser) version(tag string) (schemeVersion, error) {
+	return vp.scheme.parse(vp.parse(tag))
+}
+
+// canonical returns the version extracted from the tag in the canonical form
+// of an explicitly configured version scheme, or as it is in the tag if it
+// does not parse or no scheme is configured.
+func (vp *versionParser) canonical(tag string) string {
+	if _, ok := vp.scheme.(defaultScheme); ok {
+		return vp.parse(tag)
+	}
+
+	v, err := vp.version(tag)
+	if err != nil {
+		return vp.parse(tag)
+	}
+	return v.String()
+}
+
+// versionComponents are the parts of a version parsed as semver, along
+// with the versions that follow it.
+type versionComponents struct {
+	Version    string `json:"version" yaml:"version"`
+	Major      int64  `json:"major" yaml:"major"`
+	Minor      int64  `json:"minor" yaml:"minor"`
+	Patch      int64  `json:"patch" yaml:"patch"`
+	Prerelease string `json:"prerelease" yaml:"prerelease"`
+	Build      string `json:"build" yaml:"build"`
+
+	NextMajor string `json:"next_major" yaml:"next_major"`
+	NextMinor string `json:"next_minor" yaml:"next_minor"`
+	NextPatch string `json:"next_patch" yaml:"next_patch"`
+}
+
+// parseVersionComponents parses the version as semver, returning nil if it
+// is not a valid one. Parsing is strict, so that versions such as 1.2 or
+// 2024.05.17 are not mistaken for semver.
+func parseVersionComponents(version string) *versionComponents {
+	v, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return nil
+	}
+
+	major, minor, patch := int64(v.Major()), int64(v.Minor()), int64(v.Patch())
+
+	// A prerelease bumps to the release it leads up to if that is of the
+	// same kind, so that 2.0.0-rc.1 is followed by the major version 2.0.0
+	// rather than 3.0.0.
+	pre := v.Prerelease() != ""
+	next := func(major, minor, patch int64) string {
+		return fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	}
+
+	components := &versionComponents{
+		Version:    version,
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		Prerelease: v.Prerelease(),
+		Build:      v.Metadata(),
+
+		NextMajor: next(major+1, 0, 0),
+		NextMinor: next(major, minor+1, 0),
+		NextPatch: next(major, minor, patch+1),
+	}
+
+	if pre && minor == 0 && patch == 0 {
+		components.NextMajor = next(major, 0, 0)
+	}
+	if pre && patch == 0 {
+		components.NextMinor = next(major, minor, 0)
+	}
+	if pre {
+		components.NextPatch = next(major, minor, patch)
+	}
+
+	return components
+}
+
+// files returns the contents of the version_* and next_* files written by
+// get, keyed by their names.
+func (c *versionComponents) files() map[string]string {
+	return map[string]string{
+		"version_major":      strconv.FormatInt(c.Major, 10),
+		"version_minor":      strconv.FormatInt(c.Minor, 10),
+		"version_patch":      strconv.FormatInt(c.Patch, 10),
+		"version_prerelease": c.Prerelease,
+		"version_build":      c.Build,
+		"next_major":         c.NextMajor,
+		"next_minor":         c.NextMinor,
+		"next_patch":         c.NextPatch,
+	}
+}
+
 func getTimestamp(release *github.RepositoryRelease) time.Time {
 	if release.PublishedAt != nil {
 		return release.PublishedAt.Time